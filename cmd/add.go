@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// addCmd represents the add command
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "log a session that was not timed",
+	Long: `Log a session that was not timed, like a meeting or a whiteboard session.
+
+	Give the session as --start and --end, or as a --duration together with
+	--start, --end or --ago:
+
+	pomo add -l Meeting --start "2025-09-17 14:00" --end "15:30"
+	pomo add -l Whiteboard --duration 45m --ago 1h
+	pomo add -l Reading --start "yesterday 8pm" --duration 30m
+
+	Times can be written as "2025-09-17 14:00", "Sep 17 14:00", "14:00",
+	"2:30pm", "yesterday 16:00" or "now". A time of day alone for --end is
+	taken on the day of --start. Sessions that overlap existing ones are
+	refused unless --force is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		sessionLabel, _ := flags.GetString("label")
+		kind, _ := flags.GetString("kind")
+		force, _ := flags.GetBool("force")
+		note, _ := flags.GetString("note")
+		sessionTags, _ := flags.GetStringSlice("tag")
+		if !flags.Changed("label") {
+			sessionLabel = cfg.Label
+		}
+
+		start, end, err := resolveAddRange(cmd, time.Now().Truncate(time.Second))
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		sessionKind := timer.SessionKind(kind)
+		switch sessionKind {
+		case timer.KindWork, timer.KindShortBreak, timer.KindLongBreak:
+		default:
+			fmt.Println(color.RedString("Error: unknown kind %q, use work, short_break or long_break", kind))
+			return
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		overlapping, err := storage.OverlappingSessions(start, end)
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		if len(overlapping) > 0 && !force {
+			fmt.Println(color.RedString("Error: the session overlaps %d existing session(s):", len(overlapping)))
+			for _, s := range overlapping {
+				fmt.Printf("  #%d %s (%s) %s - %s\n", s.ID, s.Label, s.Kind, s.StartTime.Format("2006-01-02 15:04"), s.EndTime.Format("15:04"))
+			}
+			fmt.Println("Use --force to add it anyway.")
+			return
+		}
+
+		session := timer.Session{
+			Kind:            sessionKind,
+			Status:          timer.StatusCompleted,
+			Label:           sessionLabel,
+			PlannedDuration: end.Sub(start),
+			StartTime:       start,
+			EndTime:         end,
+			Note:            note,
+			Tags:            sessionTags,
+		}
+		if err := storage.SaveTimerData(&session); err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		fmt.Println(color.GreenString("Added session %d: %s %s, %s - %s", session.ID, session.Label, session.Kind,
+			session.StartTime.Format("2006-01-02 15:04:05"), session.EndTime.Format("2006-01-02 15:04:05")))
+	},
+}
+
+// work out the start and end of the session from the flags
+func resolveAddRange(cmd *cobra.Command, now time.Time) (time.Time, time.Time, error) {
+	flags := cmd.Flags()
+	startFlag, _ := flags.GetString("start")
+	endFlag, _ := flags.GetString("end")
+	duration, _ := flags.GetDuration("duration")
+	ago, _ := flags.GetDuration("ago")
+
+	hasStart, hasEnd, hasDuration := startFlag != "", endFlag != "", flags.Changed("duration")
+	if flags.Changed("ago") && (hasStart || hasEnd || !hasDuration) {
+		return time.Time{}, time.Time{}, errors.New("--ago only goes with --duration")
+	}
+	if hasDuration && duration <= 0 {
+		return time.Time{}, time.Time{}, errors.New("--duration must be greater than 0")
+	}
+	if ago < 0 {
+		return time.Time{}, time.Time{}, errors.New("--ago must not be negative")
+	}
+
+	var start, end time.Time
+	var err error
+	switch {
+	case hasStart && hasEnd && hasDuration:
+		return time.Time{}, time.Time{}, errors.New("give two of --start, --end and --duration, not all three")
+	case hasStart && hasEnd:
+		if start, err = timer.ParseTime(startFlag, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--start: %w", err)
+		}
+		if end, err = timer.ParseTime(endFlag, start); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--end: %w", err)
+		}
+	case hasStart && hasDuration:
+		if start, err = timer.ParseTime(startFlag, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--start: %w", err)
+		}
+		end = start.Add(duration)
+	case hasEnd && hasDuration:
+		if end, err = timer.ParseTime(endFlag, now); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("--end: %w", err)
+		}
+		start = end.Add(-duration)
+	case hasDuration:
+		end = now.Add(-ago)
+		start = end.Add(-duration)
+	default:
+		return time.Time{}, time.Time{}, errors.New("give --start and --end, or --duration with --start, --end or --ago")
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the session ends (%s) before it starts (%s)", end.Format("2006-01-02 15:04"), start.Format("2006-01-02 15:04"))
+	}
+	if end.After(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the session ends in the future (%s)", end.Format("2006-01-02 15:04"))
+	}
+	return start, end, nil
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+
+	addCmd.Flags().StringP("label", "l", "Work", "label for the session")
+	addCmd.Flags().String("start", "", "when the session started")
+	addCmd.Flags().String("end", "", "when the session ended")
+	addCmd.Flags().Duration("duration", 0, "how long the session lasted, e.g. 45m or 1h30m")
+	addCmd.Flags().Duration("ago", 0, "how long ago the session ended, with --duration")
+	addCmd.Flags().String("kind", string(timer.KindWork), "kind of session: work, short_break or long_break")
+	addCmd.Flags().String("note", "", "note to attach to the session")
+	addCmd.Flags().StringSliceP("tag", "t", nil, "tag to attach to the session, can be repeated")
+	addCmd.Flags().Bool("force", false, "add the session even if it overlaps existing ones")
+}
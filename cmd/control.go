@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/Dima-salang/pomolite/daemon"
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// newControlCmd builds a command that sends a single command to the daemon
+// and prints the resulting state
+func newControlCmd(command string, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   command,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := daemon.Send(socketPath, daemon.Request{Command: command})
+			if err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+			printState(*resp.State)
+		},
+	}
+}
+
+// print a one-line summary of the timer state
+func printState(state timer.State) {
+	if !state.Running {
+		fmt.Println(color.YellowString("No timer is running."))
+		return
+	}
+
+	remaining := state.Clock()
+	if state.Paused {
+		fmt.Println(color.YellowString("⏸ %s (%s) paused with %s left", state.Description, state.Phase, remaining))
+		return
+	}
+	fmt.Println(color.CyanString("▶ %s (%s) %s left", state.Description, state.Phase, remaining))
+}
+
+func init() {
+	rootCmd.AddCommand(newControlCmd(daemon.CommandPause, "pause the timer running in the daemon"))
+	rootCmd.AddCommand(newControlCmd(daemon.CommandResume, "resume the timer running in the daemon"))
+	rootCmd.AddCommand(newControlCmd(daemon.CommandSkip, "skip the current interval of the timer running in the daemon"))
+	rootCmd.AddCommand(newControlCmd(daemon.CommandStop, "stop the timer running in the daemon"))
+}
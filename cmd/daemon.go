@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Dima-salang/pomolite/daemon"
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var socketPath string
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "run Pomodoro timers in the background",
+	Long: `Run Pomodoro timers in the background.
+
+	The daemon listens on a Unix domain socket for line-delimited JSON
+	commands. Use 'pomo start --daemon' to start a timer in it and
+	'pomo pause', 'pomo resume', 'pomo skip', 'pomo stop' and 'pomo status'
+	to control it from any terminal.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		server := &daemon.Server{
+			SocketPath: socketPath,
+			Storage:    storage,
+			Observers: []timer.Observer{
+				timer.ObserverFunc(logEvent),
+				timer.NotificationObserver{Desktop: cfg.Notifications.Desktop, Bell: cfg.Notifications.Bell},
+			},
+			AfterSave: []timer.Observer{
+				timer.GoalObserver{Goals: storage, Days: dayBoundary(), WeekStart: configWeekStart(), Desktop: cfg.Notifications.Desktop, Bell: cfg.Notifications.Bell},
+			},
+		}
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		go func() {
+			<-signals
+			server.Close()
+		}()
+
+		fmt.Println(color.CyanString("PomoLite daemon listening on %s", socketPath))
+		if err := server.ListenAndServe(); err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		os.Remove(socketPath)
+	},
+}
+
+// print every event but ticks as a log line
+func logEvent(event timer.Event) {
+	if event.Type == timer.Tick {
+		return
+	}
+	fmt.Printf("%s  %-18s %s\n", event.Time.Format("2006-01-02 15:04:05"), event.Type, event.Description)
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	rootCmd.PersistentFlags().StringVar(&socketPath, "socket", daemon.DefaultSocketPath(), "path of the daemon's Unix socket")
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// the database file older versions created in the working directory
+const legacyDatabaseName = "pomodoro.db"
+
+// dbCmd represents the db command
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "manage the session database",
+}
+
+// dbPathCmd represents the db path command
+var dbPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "print the location of the session database",
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println(cfg.DatabasePath(dbPath))
+	},
+}
+
+// dbMergeCmd represents the db merge command
+var dbMergeCmd = &cobra.Command{
+	Use:   "merge [file...]",
+	Short: "merge other database files into the session database",
+	Long: `Merge other database files into the session database.
+
+	Older versions kept pomodoro.db in whatever directory pomo ran from. This
+	command copies the sessions of those files into the session database,
+	deleted ones included, skipping sessions it already has. The files are
+	only read, so one written by an older version must be upgraded first
+	with 'pomo --db <file> db migrate'. Without arguments it offers to merge
+	the pomodoro.db in the working directory. Merged files are renamed to
+	<file>.merged so they are not picked up again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		files := args
+		if len(files) == 0 {
+			if _, err := os.Stat(legacyDatabaseName); err != nil {
+				fmt.Println(color.YellowString("No %s in the working directory, pass the files to merge.", legacyDatabaseName))
+				return
+			}
+			files = []string{legacyDatabaseName}
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		canonical, _ := filepath.Abs(cfg.DatabasePath(dbPath))
+		for _, file := range files {
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+			if abs == canonical {
+				fmt.Println(color.YellowString("Skipping %s, it is the session database.", file))
+				continue
+			}
+			if !yes && !confirm(fmt.Sprintf("Merge %s into %s?", abs, canonical)) {
+				continue
+			}
+			if err := mergeDatabase(storage, abs); err != nil {
+				fmt.Println(color.RedString("Error merging %s: %v", file, err))
+				return
+			}
+		}
+	},
+}
+
+// dbMigrateCmd represents the db migrate command
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "upgrade the schema of the session database",
+	Long: `Upgrade the schema of the session database.
+
+	Every pomo command applies pending migrations when it opens the database,
+	so this is only needed to upgrade ahead of time. --status lists the
+	migrations and when each was applied, without applying any.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		status, _ := cmd.Flags().GetBool("status")
+
+		path := cfg.DatabasePath(dbPath)
+		if status {
+			if _, err := os.Stat(path); err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+			migrations, err := timer.ReadMigrationStatus(path)
+			if err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+			printMigrationStatus(path, migrations)
+			return
+		}
+
+		// a database that does not exist yet gets every migration
+		var before []timer.MigrationStatus
+		if _, err := os.Stat(path); err == nil {
+			if before, err = timer.ReadMigrationStatus(path); err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+		} else {
+			for _, m := range timer.Migrations() {
+				before = append(before, timer.MigrationStatus{Migration: m})
+			}
+		}
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		storage.Close()
+
+		applied := 0
+		for _, m := range before {
+			if !m.Applied() {
+				fmt.Printf("Applied %d: %s\n", m.Version, m.Description)
+				applied++
+			}
+		}
+		if applied == 0 {
+			fmt.Printf("%s is up to date at schema version %d.\n", path, timer.LatestSchemaVersion())
+			return
+		}
+		fmt.Println(color.GreenString("Upgraded %s to schema version %d.", path, timer.LatestSchemaVersion()))
+	},
+}
+
+// print the migrations of a database as a table
+func printMigrationStatus(path string, migrations []timer.MigrationStatus) {
+	fmt.Printf("Database: %s\n\n", path)
+
+	headers := []string{"Version", "Description", "Applied"}
+	var rows [][]string
+	pending := 0
+	for _, m := range migrations {
+		applied := color.YellowString("pending")
+		if m.Applied() {
+			applied = m.AppliedAt.Format("2006-01-02 15:04:05")
+		} else {
+			pending++
+		}
+		rows = append(rows, []string{fmt.Sprint(m.Version), m.Description, applied})
+	}
+	printTable(headers, rows)
+
+	fmt.Println()
+	if pending == 0 {
+		fmt.Println(color.GreenString("Up to date."))
+		return
+	}
+	fmt.Println(color.YellowString("%d pending migration(s), run 'pomo db migrate' to apply them.", pending))
+}
+
+// merge one database file into the session database and set it aside
+func mergeDatabase(storage *timer.SQLiteStorage, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	// only read the file, migrating it is up to the user
+	src, err := timer.NewReadOnlySQLiteStorage(path)
+	if errors.Is(err, timer.ErrSchemaOutdated) {
+		return fmt.Errorf("%w, upgrade it first with 'pomo --db %s db migrate'", err, path)
+	}
+	if err != nil {
+		return err
+	}
+	merged, skipped, err := storage.MergeFrom(src)
+	src.Close()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, path+".merged"); err != nil {
+		return err
+	}
+	fmt.Println(color.GreenString("Merged %d session(s) from %s, skipped %d already present. Renamed it to %s.merged.", merged, path, skipped, path))
+	return nil
+}
+
+// ask a yes/no question on the terminal, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbPathCmd)
+	dbCmd.AddCommand(dbMergeCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+
+	dbMergeCmd.Flags().BoolP("yes", "y", false, "merge without asking for confirmation")
+	dbMigrateCmd.Flags().Bool("status", false, "list the migrations and whether they are applied, without applying any")
+}
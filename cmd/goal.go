@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// goalCmd represents the goal command
+var goalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "set daily and weekly goals",
+	Long: `Set daily and weekly goals and follow your progress.
+
+	pomo goal set --daily 6 --label Coding
+	pomo goal set --weekly 20h
+	pomo goal list
+	pomo goal remove 2
+
+	A goal is either a number of completed pomodoros or an amount of focused
+	time, e.g. 90m or 20h. Without --label it counts the work sessions of
+	every label. Days and weeks follow timezone, day_start and week_start in
+	the config file, like streaks and the heatmap. Setting a goal again for
+	the same period and label replaces it.
+
+	'pomo stat' and 'pomo status' show the progress of every goal, and
+	completing the interval that reaches a goal sends a notification.`,
+}
+
+// goalSetCmd represents the goal set command
+var goalSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "set a daily or weekly goal",
+	Run: func(cmd *cobra.Command, args []string) {
+		label, _ := cmd.Flags().GetString("label")
+		goals, err := goalsFromFlags(cmd, label)
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		for _, goal := range goals {
+			if err := storage.SetGoal(&goal); err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+			fmt.Println(color.GreenString("🎯 Goal %d set: %s, %s", goal.ID, goal.Name(), goal.Target()))
+		}
+	},
+}
+
+// goalListCmd represents the goal list command
+var goalListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the goals and their progress",
+	Run: func(cmd *cobra.Command, args []string) {
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		progress, err := storage.GoalProgress(time.Now(), dayBoundary(), configWeekStart())
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		if machineOutput() {
+			if err := writeGoals(progress); err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+			}
+			return
+		}
+		if len(progress) == 0 {
+			fmt.Println(color.YellowString("No goals set. Set one with 'pomo goal set --daily 6'."))
+			return
+		}
+
+		headers := []string{"ID", "Period", "Label", "Target", "Progress", ""}
+		rows := make([][]string, 0, len(progress))
+		for _, p := range progress {
+			label := p.Goal.Label
+			if label == "" {
+				label = color.HiBlackString("all")
+			}
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", p.Goal.ID),
+				string(p.Goal.Period),
+				label,
+				p.Goal.Target(),
+				p.Progress(),
+				goalBar(p),
+			})
+		}
+		printTable(headers, rows)
+	},
+}
+
+// goalRemoveCmd represents the goal remove command
+var goalRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "remove a goal",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Println(color.RedString("Error: invalid goal ID %q", args[0]))
+			return
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		if err := storage.RemoveGoal(id); err != nil {
+			if errors.Is(err, timer.ErrGoalNotFound) {
+				fmt.Println(color.RedString("Error: no goal with ID %d, see 'pomo goal list'", id))
+				return
+			}
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		fmt.Println(color.GreenString("Goal %d removed.", id))
+	},
+}
+
+// build the goals given by --daily and --weekly
+func goalsFromFlags(cmd *cobra.Command, label string) ([]timer.Goal, error) {
+	var goals []timer.Goal
+	for _, period := range []timer.GoalPeriod{timer.GoalDaily, timer.GoalWeekly} {
+		value, _ := cmd.Flags().GetString(string(period))
+		if value == "" {
+			continue
+		}
+		goal := timer.Goal{Period: period, Label: label}
+		if err := parseGoalTarget(value, &goal); err != nil {
+			return nil, fmt.Errorf("--%s: %w", period, err)
+		}
+		goals = append(goals, goal)
+	}
+	if len(goals) == 0 {
+		return nil, fmt.Errorf("give --daily or --weekly, e.g. --daily 6 or --weekly 20h")
+	}
+	return goals, nil
+}
+
+// parse a goal target, a number of pomodoros or a duration such as 90m
+func parseGoalTarget(value string, goal *timer.Goal) error {
+	if n, err := strconv.Atoi(value); err == nil {
+		if n <= 0 {
+			return fmt.Errorf("the number of pomodoros must be greater than 0")
+		}
+		goal.Pomodoros = n
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%q is neither a number of pomodoros nor a duration such as 90m", value)
+	}
+	if d < time.Minute {
+		return fmt.Errorf("a duration goal must be at least a minute")
+	}
+	goal.Duration = d.Round(time.Minute)
+	return nil
+}
+
+// draw the progress of a goal as a bar, green once it is reached
+func goalBar(p timer.GoalProgress) string {
+	const width = 20
+	filled := int(p.Fraction() * width)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	if p.Done() {
+		return color.GreenString("%s ✓", bar)
+	}
+	return color.CyanString("%s %3.0f%%", bar, p.Fraction()*100)
+}
+
+// print one line per goal, for 'pomo stat' and 'pomo status'
+func printGoals(progress []timer.GoalProgress) {
+	if len(progress) == 0 {
+		return
+	}
+	fmt.Println(color.GreenString("🎯 Goals:"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, p := range progress {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", color.MagentaString(p.Goal.Name()), p.Progress(), goalBar(p))
+	}
+	w.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(goalCmd)
+	goalCmd.AddCommand(goalSetCmd, goalListCmd, goalRemoveCmd)
+
+	goalSetCmd.Flags().String("daily", "", "daily target, a number of pomodoros or a duration, e.g. 6 or 3h")
+	goalSetCmd.Flags().String("weekly", "", "weekly target, a number of pomodoros or a duration, e.g. 30 or 20h")
+	goalSetCmd.Flags().StringP("label", "l", "", "only count sessions with this label")
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// heatmapCmd represents the stat heatmap command
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap",
+	Short: "show a calendar of the focused time per day",
+	Long: `Show a calendar of a year with every day shaded by its focused time,
+	like the contributions calendar of GitHub.
+
+	pomo stat heatmap
+	pomo stat heatmap --year 2025 --label Coding
+	pomo stat heatmap --week-start sunday
+
+	The darker a day, the closer it comes to the best day of the year. Days
+	are counted in the timezone of the config file and start at its
+	day_start hour. Without colors the days are shaded with ░▒▓█.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		year, _ := cmd.Flags().GetInt("year")
+		label, _ := cmd.Flags().GetString("label")
+		weekStart, _ := cmd.Flags().GetString("week-start")
+		if weekStart == "" {
+			weekStart = cfg.WeekStart
+		}
+		firstWeekday, err := parseWeekStart(weekStart)
+		if err != nil {
+			fmt.Println(color.RedString("❌ Error: %v", err))
+			return
+		}
+
+		boundary := dayBoundary()
+		if year == 0 {
+			year = boundary.Day(time.Now()).Year()
+		}
+		first := time.Date(year, time.January, 1, 0, 0, 0, 0, boundary.Location)
+		next := first.AddDate(1, 0, 0)
+		hour := time.Duration(boundary.Hour) * time.Hour
+		timeframe := timer.TimeFrame{Start: first.Add(hour), End: next.Add(hour), Name: fmt.Sprint(year)}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("❌ Error opening database: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		totals, err := storage.DailyWorkTime(timeframe, label, boundary)
+		if err != nil {
+			fmt.Println(color.RedString("❌ Error computing focused time: %v", err))
+			return
+		}
+		if machineOutput() {
+			if err := writeDayTotals(totals); err != nil {
+				fmt.Println(color.RedString("❌ Error: %v", err))
+			}
+			return
+		}
+
+		title := fmt.Sprint(year)
+		if label != "" {
+			title += ", " + label
+		}
+		fmt.Println(color.CyanString("\n📅 Focus Heatmap: %s\n", title))
+		printHeatmap(first, next, firstWeekday, totals)
+		fmt.Println()
+
+		if len(totals) == 0 {
+			fmt.Println(color.YellowString("No focused time in %d.", year))
+			return
+		}
+		var total time.Duration
+		best := totals[0]
+		for _, t := range totals {
+			total += t.Worked
+			if t.Worked > best.Worked {
+				best = t
+			}
+		}
+		fmt.Printf("%s %s in %s, best day %s with %s\n", color.YellowString("Total:"),
+			formatDuration(total), formatDays(len(totals)), best.Day.Format("2006-01-02"), formatDuration(best.Worked))
+	},
+}
+
+// heatmapShades are the cells from no work to the best day, as colors from
+// GitHub's calendar and as characters when colors are disabled
+var heatmapShades = []struct {
+	color *color.Color
+	plain string
+}{
+	{color.New(38, 5, 237), "·"},
+	{color.New(38, 5, 22), "░"},
+	{color.New(38, 5, 28), "▒"},
+	{color.New(38, 5, 34), "▓"},
+	{color.New(38, 5, 40), "█"},
+}
+
+// draw a cell shaded by its level, 0 for no work to 4 for the best day
+func heatmapCell(level int) string {
+	shade := heatmapShades[level]
+	if color.NoColor {
+		return shade.plain
+	}
+	return shade.color.Sprint("■")
+}
+
+// print the days from first up to next as one column per week and one row
+// per weekday, with the month names above
+func printHeatmap(first, next time.Time, weekStart time.Weekday, totals []timer.DayTotal) {
+	worked := make(map[string]time.Duration, len(totals))
+	var most time.Duration
+	for _, t := range totals {
+		worked[t.Day.Format("2006-01-02")] = t.Worked
+		if t.Worked > most {
+			most = t.Worked
+		}
+	}
+
+	// the grid starts on the first day of the week that holds first
+	gridStart := first.AddDate(0, 0, -((int(first.Weekday()) - int(weekStart) + 7) % 7))
+	weeks := 0
+	for day := gridStart; day.Before(next); day = day.AddDate(0, 0, 7) {
+		weeks++
+	}
+
+	const margin = "     "
+	months := []rune(strings.Repeat(" ", weeks+3))
+	free := 0
+	for month := first; month.Before(next); month = month.AddDate(0, 1, 0) {
+		week := timer.DaysBetween(gridStart, month) / 7
+		if week < free {
+			continue
+		}
+		copy(months[week:], []rune(month.Format("Jan")))
+		free = week + 4
+	}
+	fmt.Println(margin + strings.TrimRight(string(months), " "))
+
+	for row := 0; row < 7; row++ {
+		weekday := time.Weekday((int(weekStart) + row) % 7)
+		name := ""
+		switch weekday {
+		case time.Monday, time.Wednesday, time.Friday:
+			name = weekday.String()[:3]
+		}
+
+		var line strings.Builder
+		line.WriteString(fmt.Sprintf("%-5s", name))
+		for week := 0; week < weeks; week++ {
+			day := gridStart.AddDate(0, 0, week*7+row)
+			if day.Before(first) || !day.Before(next) {
+				line.WriteString(" ")
+				continue
+			}
+			line.WriteString(heatmapCell(heatmapLevel(worked[day.Format("2006-01-02")], most)))
+		}
+		fmt.Println(strings.TrimRight(line.String(), " "))
+	}
+
+	legend := make([]string, len(heatmapShades))
+	for level := range heatmapShades {
+		legend[level] = heatmapCell(level)
+	}
+	fmt.Printf("\n%sLess %s More\n", margin, strings.Join(legend, ""))
+}
+
+// the level of a day, 0 without work and 1 to 4 by its share of the best
+// day
+func heatmapLevel(worked, most time.Duration) int {
+	if worked <= 0 || most <= 0 {
+		return 0
+	}
+	level := int(4*worked/most + 1)
+	if level > 4 {
+		return 4
+	}
+	return level
+}
+
+func parseWeekStart(value string) (time.Weekday, error) {
+	switch strings.ToLower(value) {
+	case "monday":
+		return time.Monday, nil
+	case "sunday":
+		return time.Sunday, nil
+	}
+	return 0, fmt.Errorf("unknown week start %q, use monday or sunday", value)
+}
+
+func init() {
+	statCmd.AddCommand(heatmapCmd)
+
+	heatmapCmd.Flags().Int("year", 0, "year to show, the current one by default")
+	heatmapCmd.Flags().StringP("label", "l", "", "only count sessions with this label")
+	heatmapCmd.Flags().String("week-start", "", "first day of the week, monday or sunday (default from the config file, monday)")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// labelCmd represents the label command
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "list, rename and merge labels",
+	Long: `List, rename and merge the labels of your sessions.
+
+	pomo label list
+	pomo label rename code Coding
+	pomo label merge coding code --into Coding
+
+	Labels are matched exactly, so "coding" and "Coding" are different
+	labels. Renames and merges change every session with the label, deleted
+	ones included, in a single transaction, and move the goals of the label
+	along. When the new label ends up with two daily or weekly goals, the one
+	it already had is kept, or else the one of the first label given.
+	--dry-run shows how many sessions each change would affect without
+	changing anything.`,
+}
+
+// labelListCmd represents the label list command
+var labelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the labels and how much each is used",
+	Run: func(cmd *cobra.Command, args []string) {
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		labels, err := storage.ListLabels()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		if len(labels) == 0 {
+			fmt.Println(color.YellowString("No labels found."))
+			return
+		}
+
+		headers := []string{"Label", "Sessions", "Work Time", "Last Used"}
+		rows := make([][]string, 0, len(labels))
+		for _, l := range labels {
+			rows = append(rows, []string{
+				color.GreenString(l.Label),
+				fmt.Sprintf("%d", l.Sessions),
+				formatDuration(l.WorkDuration),
+				l.LastUsed.Format("2006-01-02"),
+			})
+		}
+		printTable(headers, rows)
+	},
+}
+
+// labelRenameCmd represents the label rename command
+var labelRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "rename a label on every session",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		relabel([]string{args[0]}, args[1], dryRun)
+	},
+}
+
+// labelMergeCmd represents the label merge command
+var labelMergeCmd = &cobra.Command{
+	Use:   "merge <label>... --into <label>",
+	Short: "merge several labels into one",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		into, _ := cmd.Flags().GetString("into")
+		if into == "" {
+			fmt.Println(color.RedString("Error: --into is required"))
+			return
+		}
+		relabel(args, into, dryRun)
+	},
+}
+
+// move the sessions of the labels in from to the label to and report the
+// number of sessions each label had
+func relabel(from []string, to string, dryRun bool) {
+	storage, err := openStorage()
+	if err != nil {
+		fmt.Println(color.RedString("Error: %v", err))
+		return
+	}
+	defer storage.Close()
+
+	counts, err := storage.RelabelSessions(from, to, dryRun)
+	if err != nil {
+		fmt.Println(color.RedString("Error: %v", err))
+		return
+	}
+
+	labels := make([]string, 0, len(counts))
+	total := 0
+	for label, n := range counts {
+		labels = append(labels, label)
+		total += n
+	}
+	sort.Strings(labels)
+
+	verb := "Relabelled"
+	if dryRun {
+		verb = "Would relabel"
+	}
+	for _, label := range labels {
+		fmt.Printf("  %s → %s: %d session(s)\n", label, to, counts[label])
+	}
+	if total == 0 {
+		fmt.Println(color.YellowString("No sessions have the label(s) %v.", from))
+		return
+	}
+	fmt.Println(color.GreenString("%s %d session(s) as %q.", verb, total, to))
+}
+
+func init() {
+	rootCmd.AddCommand(labelCmd)
+	labelCmd.AddCommand(labelListCmd)
+	labelCmd.AddCommand(labelRenameCmd)
+	labelCmd.AddCommand(labelMergeCmd)
+
+	labelCmd.PersistentFlags().Bool("dry-run", false, "show how many sessions would change without changing them")
+	labelMergeCmd.Flags().String("into", "", "label to merge the others into")
+}
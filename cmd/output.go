@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+	"gopkg.in/yaml.v3"
+)
+
+// output formats of the --output flag
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+	outputYAML  = "yaml"
+)
+
+var outputFormat string
+
+// checkOutputFormat validates the --output flag
+func checkOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputCSV, outputTSV, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, use table, json, csv, tsv or yaml", outputFormat)
+}
+
+// machineOutput reports whether the output is for scripts rather than people
+func machineOutput() bool {
+	return outputFormat != outputTable
+}
+
+// write v to stdout as indented JSON
+func writeJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// write v to stdout as YAML
+func writeYAML(v any) error {
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// write the rows to stdout as CSV or, for tsv, tab separated values
+func writeDelimited(headers []string, rows [][]string) error {
+	if outputFormat == outputTSV {
+		// tabs and newlines inside a field would break the columns
+		clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+		fmt.Println(strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fields := make([]string, len(row))
+			for i, field := range row {
+				fields[i] = clean.Replace(field)
+			}
+			fmt.Println(strings.Join(fields, "\t"))
+		}
+		return nil
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(headers); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// write the sessions in the --output format; json and yaml are lists of
+// objects, csv and tsv have one row per session
+func writeSessions(sessions []timer.Session) error {
+	if sessions == nil {
+		sessions = []timer.Session{}
+	}
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(sessions)
+	case outputYAML:
+		return writeYAML(sessions)
+	}
+
+	headers := []string{"id", "label", "kind", "status", "start_time", "end_time", "duration_seconds", "net_seconds", "paused_seconds", "planned_seconds", "pauses", "tags", "note"}
+	rows := make([][]string, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, []string{
+			fmt.Sprint(s.ID),
+			s.Label,
+			string(s.Kind),
+			string(s.Status),
+			s.StartTime.Format(time.RFC3339),
+			s.EndTime.Format(time.RFC3339),
+			fmt.Sprint(int64(s.Duration().Seconds())),
+			fmt.Sprint(int64(s.NetDuration().Seconds())),
+			fmt.Sprint(int64(s.PausedDuration().Seconds())),
+			fmt.Sprint(int64(s.PlannedDuration.Seconds())),
+			fmt.Sprint(len(s.Pauses)),
+			strings.Join(s.Tags, ","),
+			s.Note,
+		})
+	}
+	return writeDelimited(headers, rows)
+}
+
+// write the statistics in the --output format; csv and tsv are in long
+// form, one metric per row, with the label for per label metrics
+func writeStats(stats *timer.PomoStats) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(stats)
+	case outputYAML:
+		return writeYAML(stats)
+	}
+
+	headers := []string{"metric", "label", "value"}
+	rows := [][]string{
+		{"total_sessions", "", fmt.Sprint(stats.TotalSessions)},
+		{"total_work_seconds", "", fmt.Sprint(int64(stats.TotalWorkDuration.Seconds()))},
+		{"total_break_seconds", "", fmt.Sprint(int64(stats.TotalBreakDuration.Seconds()))},
+		{"average_session_seconds", "", fmt.Sprint(int64(stats.AverageSessionDuration.Seconds()))},
+		{"longest_session_seconds", "", fmt.Sprint(int64(stats.LongestSession.Seconds()))},
+		{"shortest_session_seconds", "", fmt.Sprint(int64(stats.ShortestSession.Seconds()))},
+		{"median_session_seconds", "", fmt.Sprint(int64(stats.MedianSession.Seconds()))},
+		{"p25_session_seconds", "", fmt.Sprint(int64(stats.SessionPercentiles[25].Seconds()))},
+		{"p75_session_seconds", "", fmt.Sprint(int64(stats.SessionPercentiles[75].Seconds()))},
+		{"p90_session_seconds", "", fmt.Sprint(int64(stats.SessionPercentiles[90].Seconds()))},
+		{"completion_rate", "", fmt.Sprintf("%.4f", stats.CompletionRate)},
+	}
+	for _, l := range stats.Labels() {
+		rows = append(rows,
+			[]string{"sessions", l.Label, fmt.Sprint(l.Sessions)},
+			[]string{"work_seconds", l.Label, fmt.Sprint(l.WorkSeconds)},
+		)
+		if l.PlannedAchieved != nil {
+			rows = append(rows, []string{"planned_achieved", l.Label, fmt.Sprintf("%.4f", *l.PlannedAchieved)})
+		}
+	}
+	for _, p := range stats.Goals {
+		rows = append(rows, []string{"goal_" + string(p.Goal.Period), p.Goal.Label, fmt.Sprintf("%.4f", p.Fraction())})
+	}
+	for _, s := range stats.Streaks {
+		rows = append(rows,
+			[]string{"streak_current", s.Label, fmt.Sprint(s.Current)},
+			[]string{"streak_longest", s.Label, fmt.Sprint(s.Longest)},
+		)
+	}
+	if d := stats.Distribution; d != nil {
+		// the label column holds the hour or the weekday
+		for _, b := range d.Buckets {
+			rows = append(rows,
+				[]string{"work_seconds_by_" + string(d.By), b.Name, fmt.Sprint(int64(b.Worked.Seconds()))},
+				[]string{"pomodoros_by_" + string(d.By), b.Name, fmt.Sprintf("%.4f", b.Pomodoros)},
+			)
+		}
+	}
+	return writeDelimited(headers, rows)
+}
+
+// write the progress of the goals in the --output format
+func writeGoals(progress []timer.GoalProgress) error {
+	if progress == nil {
+		progress = []timer.GoalProgress{}
+	}
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(progress)
+	case outputYAML:
+		return writeYAML(progress)
+	}
+
+	headers := []string{"id", "period", "label", "target_pomodoros", "target_seconds", "pomodoros", "worked_seconds", "fraction", "done"}
+	rows := make([][]string, 0, len(progress))
+	for _, p := range progress {
+		rows = append(rows, []string{
+			fmt.Sprint(p.Goal.ID),
+			string(p.Goal.Period),
+			p.Goal.Label,
+			fmt.Sprint(p.Goal.Pomodoros),
+			fmt.Sprint(int64(p.Goal.Duration.Seconds())),
+			fmt.Sprint(p.Pomodoros),
+			fmt.Sprint(int64(p.Worked.Seconds())),
+			fmt.Sprintf("%.4f", p.Fraction()),
+			fmt.Sprint(p.Done()),
+		})
+	}
+	return writeDelimited(headers, rows)
+}
+
+// write the focused time per day in the --output format
+func writeDayTotals(totals []timer.DayTotal) error {
+	if totals == nil {
+		totals = []timer.DayTotal{}
+	}
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(totals)
+	case outputYAML:
+		return writeYAML(totals)
+	}
+
+	headers := []string{"date", "work_seconds"}
+	rows := make([][]string, 0, len(totals))
+	for _, t := range totals {
+		rows = append(rows, []string{t.Day.Format("2006-01-02"), fmt.Sprint(int64(t.Worked.Seconds()))})
+	}
+	return writeDelimited(headers, rows)
+}
+
+// write a comparison of two periods in the --output format; csv and tsv
+// have one row per metric, with an empty percent when the previous period
+// has nothing to compare with
+func writeComparison(c *timer.Comparison) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(c)
+	case outputYAML:
+		return writeYAML(c)
+	}
+
+	headers := []string{"metric", "label", "unit", "current", "previous", "change", "percent"}
+	rows := make([][]string, 0, len(c.Deltas))
+	for _, d := range c.Deltas {
+		percent := ""
+		if p, ok := d.Percent(); ok {
+			percent = fmt.Sprintf("%.4f", p)
+		}
+		rows = append(rows, []string{
+			d.Metric,
+			d.Label,
+			string(d.Unit),
+			fmt.Sprint(d.Current),
+			fmt.Sprint(d.Previous),
+			fmt.Sprint(d.Change()),
+			percent,
+		})
+	}
+	return writeDelimited(headers, rows)
+}
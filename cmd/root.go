@@ -6,7 +6,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/Dima-salang/pomolite/config"
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +27,12 @@ const asciiArt = `
 
 `
 
+var cfgFile string
+var dbPath string
+
+// cfg holds the settings from the config file, on top of the defaults
+var cfg = config.Default()
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "pomo",
@@ -50,6 +60,56 @@ Developed by PUTAN LUIS GABRIELLE <luisgabrielle1026@gmail.com>
 	// Run: func(cmd *cobra.Command, args []string) { },
 }
 
+// initConfig reads in the config file, if there is one
+func initConfig() {
+	path := cfgFile
+	if path == "" {
+		path = config.DefaultPath()
+	}
+
+	loaded, err := config.Load(path, cfgFile != "")
+	if err != nil {
+		fmt.Println(color.RedString("Error loading config: %v", err))
+		os.Exit(1)
+	}
+	cfg = loaded
+
+	if !cfg.Colors {
+		color.NoColor = true
+	}
+
+	if err := checkOutputFormat(); err != nil {
+		fmt.Println(color.RedString("Error: %v", err))
+		os.Exit(1)
+	}
+}
+
+// openStorage opens the session database, creating its directory if needed
+func openStorage() (*timer.SQLiteStorage, error) {
+	path := cfg.DatabasePath(dbPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	hintScatteredDatabase(path)
+	return timer.NewSQLiteStorage(path)
+}
+
+// hintScatteredDatabase points out a pomodoro.db in the working directory
+// left behind by versions that kept the database there
+func hintScatteredDatabase(canonical string) {
+	local, err := filepath.Abs(legacyDatabaseName)
+	if err != nil {
+		return
+	}
+	if abs, err := filepath.Abs(canonical); err == nil && abs == local {
+		return
+	}
+	if _, err := os.Stat(local); err != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, color.YellowString("Found an old %s here, sessions are now kept in %s. Run 'pomo db merge' to merge it.", local, canonical))
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -64,7 +124,10 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.PomoLite.yaml)")
+	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/pomolite/config.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, "output format of sessions and stat: table, json, csv, tsv or yaml")
+	rootCmd.PersistentFlags().StringVar(&dbPath, "db", "", "session database (default is $POMOLITE_DB, the config file, or $XDG_DATA_HOME/pomolite/pomodoro.db)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
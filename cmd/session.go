@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// sessionCmd represents the session command
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "edit, delete or restore a single session",
+	Long: `Edit, delete or restore a single session, by the ID shown by 'pomo sessions'.
+
+	pomo session edit 42 --label Writing --end 15:30 --note "typo fixed"
+	pomo session delete 42
+	pomo session restore 42
+
+	Deleting a session hides it from 'pomo sessions' and 'pomo stat' but
+	keeps it in the database, so it can be restored. 'pomo sessions --deleted'
+	lists the deleted sessions.`,
+}
+
+// sessionEditCmd represents the session edit command
+var sessionEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "change the label, times, note or tags of a session",
+	Long: `Change the label, times, note or tags of a session.
+
+	Times take the same formats as 'pomo add'. A time of day alone for
+	--start is taken on the day the session started, and for --end on the
+	day of the new start. Pauses that no longer fall within the session are
+	dropped. Times that overlap other sessions are refused unless --force
+	is given. --tag replaces the tags of the session and can be repeated;
+	--tag "" removes them all.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseSessionID(args[0])
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		session, err := storage.GetSession(id)
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		if session.Deleted() {
+			fmt.Println(color.RedString("Error: session %d is deleted, restore it first", id))
+			return
+		}
+
+		flags := cmd.Flags()
+		if !flags.Changed("label") && !flags.Changed("start") && !flags.Changed("end") && !flags.Changed("note") && !flags.Changed("tag") {
+			fmt.Println(color.YellowString("Nothing to change, give --label, --start, --end, --note or --tag."))
+			return
+		}
+		if flags.Changed("tag") {
+			session.Tags, _ = flags.GetStringSlice("tag")
+		}
+		if flags.Changed("label") {
+			session.Label, _ = flags.GetString("label")
+			if session.Label == "" {
+				fmt.Println(color.RedString("Error: the label must not be empty"))
+				return
+			}
+		}
+		if flags.Changed("note") {
+			session.Note, _ = flags.GetString("note")
+		}
+		if flags.Changed("start") {
+			value, _ := flags.GetString("start")
+			if session.StartTime, err = timer.ParseTime(value, session.StartTime); err != nil {
+				fmt.Println(color.RedString("Error: --start: %v", err))
+				return
+			}
+		}
+		if flags.Changed("end") {
+			value, _ := flags.GetString("end")
+			if session.EndTime, err = timer.ParseTime(value, session.StartTime); err != nil {
+				fmt.Println(color.RedString("Error: --end: %v", err))
+				return
+			}
+		}
+
+		if flags.Changed("start") || flags.Changed("end") {
+			force, _ := flags.GetBool("force")
+			overlapping, err := storage.OverlappingSessions(session.StartTime, session.EndTime)
+			if err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
+				return
+			}
+			var others []timer.Session
+			for _, s := range overlapping {
+				if s.ID != session.ID {
+					others = append(others, s)
+				}
+			}
+			if len(others) > 0 && !force {
+				fmt.Println(color.RedString("Error: the new times overlap %d other session(s):", len(others)))
+				for _, s := range others {
+					fmt.Printf("  #%d %s (%s) %s - %s\n", s.ID, s.Label, s.Kind, s.StartTime.Format("2006-01-02 15:04"), s.EndTime.Format("15:04"))
+				}
+				fmt.Println("Use --force to save them anyway.")
+				return
+			}
+		}
+
+		if err := storage.UpdateSession(session); err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		fmt.Println(color.GreenString("Updated session %d: %s, %s - %s", session.ID, session.Label,
+			session.StartTime.Format("2006-01-02 15:04:05"), session.EndTime.Format("2006-01-02 15:04:05")))
+	},
+}
+
+// sessionDeleteCmd represents the session delete command
+var sessionDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "delete a session, undoable with restore",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseSessionID(args[0])
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		if err := storage.DeleteSession(id); err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		fmt.Println(color.GreenString("Deleted session %d. Undo with 'pomo session restore %d'.", id, id))
+	},
+}
+
+// sessionRestoreCmd represents the session restore command
+var sessionRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "restore a deleted session",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := parseSessionID(args[0])
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+
+		storage, err := openStorage()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		defer storage.Close()
+
+		if err := storage.RestoreSession(id); err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+		fmt.Println(color.GreenString("Restored session %d.", id))
+	},
+}
+
+// parse the id argument of the session subcommands
+func parseSessionID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid session id %q", arg)
+	}
+	return id, nil
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionEditCmd)
+	sessionCmd.AddCommand(sessionDeleteCmd)
+	sessionCmd.AddCommand(sessionRestoreCmd)
+
+	sessionEditCmd.Flags().StringP("label", "l", "", "new label")
+	sessionEditCmd.Flags().String("start", "", "new start time")
+	sessionEditCmd.Flags().String("end", "", "new end time")
+	sessionEditCmd.Flags().String("note", "", "note to attach to the session, empty to clear it")
+	sessionEditCmd.Flags().StringSliceP("tag", "t", nil, "replace the tags of the session, can be repeated")
+	sessionEditCmd.Flags().Bool("force", false, "save new times even if they overlap other sessions")
+}
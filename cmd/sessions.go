@@ -18,117 +18,231 @@ var sessionsCmd = &cobra.Command{
 	Long: `List the sessions.
 
 	This command lists all the sessions saved in the database.
-	Each session includes the label, start time, and end time.
-	The sessions are ordered by start time in descending order.`,
+	Each session includes the label, kind (work, short_break or long_break),
+	status (completed, aborted or skipped), start time, end time, the gross
+	duration, the net duration without pauses, the planned duration, the
+	number of pauses, its tags and its note.
+	The sessions are ordered by start time in descending order.
+
+	Filters narrow the list down and can be combined:
+
+	pomo sessions --label Coding --since "2025-09-01" --until "2025-10-01"
+	pomo sessions --label-glob 'cod*' --min 20m --status completed
+	pomo sessions --label-regex '(?i)^read' --tag book --sort duration
+	pomo sessions --limit 20 --offset 20
+
+	--since and --until take the same formats as 'pomo add' and bound the
+	start time. --min and --max bound the net duration. --status, --kind and
+	--tag can be repeated; a session must have every tag given.
+	--deleted lists the deleted sessions instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		limit, _ := cmd.Flags().GetInt("limit")
-		storage, err := timer.NewSQLiteStorage("./pomodoro.db")
+		query, err := sessionQueryFromFlags(cmd, time.Now())
 		if err != nil {
 			fmt.Println(color.RedString("Error: %v", err))
 			return
 		}
-		defer storage.Close()
 
-		sessions, err := storage.ListSessions(limit)
+		storage, err := openStorage()
 		if err != nil {
 			fmt.Println(color.RedString("Error: %v", err))
 			return
 		}
-		if len(sessions) == 0 {
-			fmt.Println(color.YellowString("No sessions found."))
-			return
-		}
+		defer storage.Close()
 
-		// Regex to strip ANSI color codes for length calculations
-		ansi := regexp.MustCompile("\x1b\\[[0-9;]*m")
-		visibleLen := func(s string) int {
-			return len([]rune(ansi.ReplaceAllString(s, "")))
+		sessions, err := storage.QuerySessions(query)
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
 		}
-		padRightANSI := func(s string, width int) string {
-			v := visibleLen(s)
-			if v >= width {
-				return s
+		if machineOutput() {
+			if err := writeSessions(sessions); err != nil {
+				fmt.Println(color.RedString("Error: %v", err))
 			}
-			return s + strings.Repeat(" ", width-v)
+			return
 		}
-
-		// Compute column widths (start/end use fixed format length 19)
-		idW := visibleLen("ID")
-		labelW := visibleLen("Label")
-		startW := visibleLen("Start Time") // header, but we'll ensure at least 19
-		endW := visibleLen("End Time")
-		durW := visibleLen("Duration")
-
-		// Iterate to figure out max widths
-		for _, s := range sessions {
-			idStr := fmt.Sprintf("%d", s.ID)
-			if len(idStr) > idW {
-				idW = len(idStr)
-			}
-			if visibleLen(s.Label) > labelW {
-				labelW = visibleLen(s.Label)
-			}
-			startStr := s.StartTime.Format("2006-01-02 15:04:05")
-			if visibleLen(startStr) > startW {
-				startW = visibleLen(startStr)
-			}
-			endStr := s.EndTime.Format("2006-01-02 15:04:05")
-			if visibleLen(endStr) > endW {
-				endW = visibleLen(endStr)
-			}
-			durStr := s.EndTime.Sub(s.StartTime).Round(time.Second).String()
-			if visibleLen(durStr) > durW {
-				durW = visibleLen(durStr)
-			}
+		if len(sessions) == 0 {
+			fmt.Println(color.YellowString("No sessions found."))
+			return
 		}
 
-		// Header (colored)
-		hID := color.CyanString("ID")
-		hLabel := color.CyanString("Label")
-		hStart := color.CyanString("Start Time")
-		hEnd := color.CyanString("End Time")
-		hDur := color.CyanString("Duration")
-
-		// Print header and separator
-		sepLen := idW + labelW + startW + endW + durW + 4*2 // 4 gaps of "  "
-		fmt.Printf("%s  %s  %s  %s  %s\n",
-			padRightANSI(hID, idW),
-			padRightANSI(hLabel, labelW),
-			padRightANSI(hStart, startW),
-			padRightANSI(hEnd, endW),
-			padRightANSI(hDur, durW),
-		)
-		fmt.Println(strings.Repeat("-", sepLen))
+		headers := []string{"ID", "Label", "Kind", "Status", "Start Time", "End Time", "Gross", "Net", "Planned", "Pauses", "Tags", "Note"}
+		rows := make([][]string, 0, len(sessions))
 
 		// Rows (alternating label color)
 		for i, s := range sessions {
-			idStr := fmt.Sprintf("%d", s.ID)
 			labelColored := color.GreenString(s.Label)
 			if i%2 == 1 {
 				labelColored = color.YellowString(s.Label)
 			}
-			startStr := s.StartTime.Format("2006-01-02 15:04:05")
-			endStr := s.EndTime.Format("2006-01-02 15:04:05")
-			durStr := s.EndTime.Sub(s.StartTime).Round(time.Second).String()
-			durColored := color.MagentaString("%s", durStr)
-
-			fmt.Printf("%s  %s  %s  %s  %s\n",
-				padRightANSI(idStr, idW),
-				padRightANSI(labelColored, labelW),
-				padRightANSI(startStr, startW),
-				padRightANSI(endStr, endW),
-				padRightANSI(durColored, durW),
-			)
+			kindStr := string(s.Kind)
+			if s.Kind.IsBreak() {
+				kindStr = color.HiBlackString(kindStr)
+			}
+
+			statusStr := string(s.Status)
+			switch s.Status {
+			case timer.StatusAborted:
+				statusStr = color.RedString(statusStr)
+			case timer.StatusSkipped:
+				statusStr = color.YellowString(statusStr)
+			}
+
+			plannedStr := "-"
+			if s.PlannedDuration > 0 {
+				plannedStr = s.PlannedDuration.Round(time.Second).String()
+			}
+
+			rows = append(rows, []string{
+				fmt.Sprintf("%d", s.ID),
+				labelColored,
+				kindStr,
+				statusStr,
+				s.StartTime.Format("2006-01-02 15:04:05"),
+				s.EndTime.Format("2006-01-02 15:04:05"),
+				s.Duration().Round(time.Second).String(),
+				color.MagentaString("%s", s.NetDuration().Round(time.Second).String()),
+				plannedStr,
+				fmt.Sprintf("%d", len(s.Pauses)),
+				color.CyanString(strings.Join(s.Tags, ",")),
+				s.Note,
+			})
 		}
+		printTable(headers, rows)
 
-		// Footer note if --count was used
-		if limit > 0 {
+		// Footer note if --limit was used
+		if query.Limit > 0 {
 			fmt.Println()
-			fmt.Println(color.HiBlackString("Showing last %d session(s).", limit))
+			fmt.Println(color.HiBlackString("Showing %d session(s) from offset %d. Next page: --offset %d", len(sessions), query.Offset, query.Offset+query.Limit))
 		}
 	},
 }
 
+// build the query of the sessions command from its flags
+func sessionQueryFromFlags(cmd *cobra.Command, now time.Time) (timer.SessionQuery, error) {
+	flags := cmd.Flags()
+	var q timer.SessionQuery
+
+	q.Limit, _ = flags.GetInt("limit")
+	q.Offset, _ = flags.GetInt("offset")
+	q.Deleted, _ = flags.GetBool("deleted")
+	q.Ascending, _ = flags.GetBool("asc")
+	q.MinDuration, _ = flags.GetDuration("min")
+	q.MaxDuration, _ = flags.GetDuration("max")
+	q.Tags, _ = flags.GetStringSlice("tag")
+	if q.Limit < 0 || q.Offset < 0 {
+		return q, fmt.Errorf("--limit and --offset must not be negative")
+	}
+	if q.MaxDuration > 0 && q.MinDuration > q.MaxDuration {
+		return q, fmt.Errorf("--min is longer than --max")
+	}
+
+	matches := 0
+	for flag, match := range map[string]timer.LabelMatch{
+		"label":       timer.LabelExact,
+		"label-glob":  timer.LabelGlob,
+		"label-regex": timer.LabelRegex,
+	} {
+		if flags.Changed(flag) {
+			q.Label, _ = flags.GetString(flag)
+			q.LabelMatch = match
+			matches++
+		}
+	}
+	if matches > 1 {
+		return q, fmt.Errorf("give only one of --label, --label-glob and --label-regex")
+	}
+
+	if since, _ := flags.GetString("since"); since != "" {
+		t, err := timer.ParseTime(since, now)
+		if err != nil {
+			return q, fmt.Errorf("--since: %w", err)
+		}
+		q.Since = t
+	}
+	if until, _ := flags.GetString("until"); until != "" {
+		t, err := timer.ParseTime(until, now)
+		if err != nil {
+			return q, fmt.Errorf("--until: %w", err)
+		}
+		q.Until = t
+	}
+
+	statuses, _ := flags.GetStringSlice("status")
+	for _, status := range statuses {
+		switch timer.SessionStatus(status) {
+		case timer.StatusCompleted, timer.StatusAborted, timer.StatusSkipped:
+			q.Statuses = append(q.Statuses, timer.SessionStatus(status))
+		default:
+			return q, fmt.Errorf("unknown status %q, use completed, aborted or skipped", status)
+		}
+	}
+	kinds, _ := flags.GetStringSlice("kind")
+	for _, kind := range kinds {
+		switch timer.SessionKind(kind) {
+		case timer.KindWork, timer.KindShortBreak, timer.KindLongBreak:
+			q.Kinds = append(q.Kinds, timer.SessionKind(kind))
+		default:
+			return q, fmt.Errorf("unknown kind %q, use work, short_break or long_break", kind)
+		}
+	}
+
+	sort, _ := flags.GetString("sort")
+	switch order := timer.SessionOrder(sort); order {
+	case timer.OrderStart, timer.OrderEnd, timer.OrderDuration, timer.OrderLabel, timer.OrderID:
+		q.OrderBy = order
+	default:
+		return q, fmt.Errorf("unknown sort order %q, use start, end, duration, label or id", sort)
+	}
+	return q, nil
+}
+
+// Regex to strip ANSI color codes for length calculations
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func visibleLen(s string) int {
+	return len([]rune(ansi.ReplaceAllString(s, "")))
+}
+
+func padRightANSI(s string, width int) string {
+	v := visibleLen(s)
+	if v >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-v)
+}
+
+// print the rows aligned under cyan headers, ignoring color codes when
+// computing column widths
+func printTable(headers []string, rows [][]string) {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = visibleLen(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if visibleLen(cell) > widths[i] {
+				widths[i] = visibleLen(cell)
+			}
+		}
+	}
+
+	cells := make([]string, len(headers))
+	sepLen := 2 * (len(headers) - 1) // gaps of "  "
+	for i, h := range headers {
+		cells[i] = padRightANSI(color.CyanString(h), widths[i])
+		sepLen += widths[i]
+	}
+	fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+	fmt.Println(strings.Repeat("-", sepLen))
+
+	for _, row := range rows {
+		for i, cell := range row {
+			cells[i] = padRightANSI(cell, widths[i])
+		}
+		fmt.Println(strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(sessionsCmd)
 
@@ -142,4 +256,18 @@ func init() {
 	// is called directly, e.g.:
 	// sessionsCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	sessionsCmd.Flags().IntP("limit", "l", 0, "number of sessions to list")
+	sessionsCmd.Flags().Int("offset", 0, "number of sessions to skip, for paging with --limit")
+	sessionsCmd.Flags().String("label", "", "only sessions with exactly this label")
+	sessionsCmd.Flags().String("label-glob", "", "only sessions whose label matches a shell pattern, e.g. 'cod*'")
+	sessionsCmd.Flags().String("label-regex", "", "only sessions whose label matches a regular expression")
+	sessionsCmd.Flags().String("since", "", "only sessions that started at or after this time")
+	sessionsCmd.Flags().String("until", "", "only sessions that started before this time")
+	sessionsCmd.Flags().Duration("min", 0, "only sessions with at least this net duration, e.g. 20m")
+	sessionsCmd.Flags().Duration("max", 0, "only sessions with at most this net duration")
+	sessionsCmd.Flags().StringSlice("status", nil, "only sessions with this status: completed, aborted or skipped")
+	sessionsCmd.Flags().StringSlice("kind", nil, "only sessions of this kind: work, short_break or long_break")
+	sessionsCmd.Flags().StringSlice("tag", nil, "only sessions with this tag")
+	sessionsCmd.Flags().String("sort", string(timer.OrderStart), "sort by start, end, duration, label or id")
+	sessionsCmd.Flags().Bool("asc", false, "sort in ascending order")
+	sessionsCmd.Flags().Bool("deleted", false, "list the deleted sessions, which 'pomo session restore' brings back")
 }
@@ -1,64 +1,240 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/Dima-salang/pomolite/config"
+	"github.com/Dima-salang/pomolite/daemon"
 	"github.com/Dima-salang/pomolite/timer"
 	"github.com/eiannone/keyboard"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var minutes int
 var breakMinutes int
 var label string
+var longBreakMinutes int
+var longEvery int
+var cycles int
+var preset string
+var inDaemon bool
+var resume bool
+var tags []string
 
 // startCmd represents the start command
 var startCmd = &cobra.Command{
-	Use:   "start",
+	Use:   "start [preset]",
 	Short: "start a Pomodoro Timer.",
 	Long: `Start a Pomodoro Timer.
 
 	FLAGS:
 	-l : label for the work session
 	-m : minutes of work
-	-b : minutes of break`,
+	-b : minutes of break
+	--long-break : minutes of the long break (0 disables long breaks)
+	--long-every : number of completed work intervals before a long break
+	--cycles : stop after this many completed work intervals (0 runs until quit)
+	--preset : start a named preset from the config file
+	--daemon : run the timer in the background daemon instead
+	--resume : pick up a timer that was interrupted by a crash or reboot
+	--tag : tag every session of the timer, can be repeated
+
+	The running timer is checkpointed to a state file every second. When
+	'pomo start' finds the checkpoint of a timer that was interrupted, the
+	partial interval is saved as aborted, unless --resume is given.
+
+	Settings that are not given as flags come from the preset, if one is
+	named, and otherwise from the config file, e.g. 'pomo start deep-work'.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := resolveStartSettings(cmd, args); err != nil {
+			fmt.Println("Error: ", err)
+			return
+		}
+
 		// check for the validity of the input
 		if !timer.CheckInput(minutes, breakMinutes) {
 			return
 		}
-		var storage timer.Storage
-		storage, err := timer.NewSQLiteStorage("./pomodoro.db")
+		if !timer.CheckLongBreakInput(longBreakMinutes, longEvery) {
+			return
+		}
+		if inDaemon {
+			resp, err := daemon.Send(socketPath, daemon.Request{
+				Command:          daemon.CommandStart,
+				Label:            label,
+				WorkMinutes:      minutes,
+				BreakMinutes:     breakMinutes,
+				LongBreakMinutes: longBreakMinutes,
+				LongEvery:        longEvery,
+				Cycles:           cycles,
+				Tags:             tags,
+			})
+			if err != nil {
+				fmt.Println("Error: ", err)
+				return
+			}
+			printState(*resp.State)
+			return
+		}
 
+		storage, err := openStorage()
 		if err != nil {
 			fmt.Println("Error: ", err)
 			return
 		}
 		defer storage.Close()
 
-		totalWorkDuration := time.Duration(minutes)*time.Minute
+		statePath := timer.DefaultStatePath()
+		checkpoint, err := timer.ReadStateFile(statePath)
+		if errors.Is(err, timer.ErrCorruptStateFile) {
+			// the checkpoint is lost, which must not keep the timer from
+			// starting again
+			fmt.Println(color.YellowString("Warning: %v", err))
+			if moved, err := timer.MoveCorruptStateFile(statePath); err != nil {
+				fmt.Println(color.YellowString("Warning: %v", err))
+			} else {
+				fmt.Println(color.YellowString("Moved it to %s and starting afresh.", moved))
+			}
+			checkpoint, err = timer.State{}, nil
+		}
+		if err != nil {
+			fmt.Println("Error: ", err)
+			return
+		}
+		if checkpoint.Running && checkpoint.Alive() {
+			fmt.Printf("Error: a timer is already running in process %d\n", checkpoint.PID)
+			return
+		}
+		if resume && !checkpoint.Interrupted() {
+			fmt.Println("Error: there is no interrupted timer to resume")
+			return
+		}
+		if !resume && checkpoint.Interrupted() {
+			recovered := checkpoint.RecoveredSession()
+			if err := storage.SaveTimerData(&recovered); err != nil {
+				fmt.Println("Error: ", err)
+				return
+			}
+			fmt.Println(color.YellowString("Saved the interrupted %s as aborted. Use --resume to pick up an interrupted timer instead.", checkpoint.Description))
+		}
+
+		totalWorkDuration := time.Duration(minutes) * time.Minute
 		totalBreakDuration := time.Duration(breakMinutes) * time.Minute
 
 		pt := timer.NewPomodoroTimer(totalWorkDuration, totalBreakDuration, label)
+		pt.LongBreakDuration = time.Duration(longBreakMinutes) * time.Minute
+		pt.LongBreakEvery = longEvery
+		pt.Tags = tags
+		if resume {
+			// the interrupted timer's settings win over the flags
+			pt.WorkLabel = checkpoint.Label
+			pt.WorkDuration = time.Duration(checkpoint.WorkSeconds) * time.Second
+			pt.BreakDuration = time.Duration(checkpoint.BreakSeconds) * time.Second
+			pt.LongBreakDuration = time.Duration(checkpoint.LongBreakSeconds) * time.Second
+			pt.LongBreakEvery = checkpoint.LongEvery
+			pt.Tags = checkpoint.Tags
+		}
+		pt.Subscribe(timer.NewTerminalObserver())
+		pt.Subscribe(timer.NewStateFile(statePath, pt))
+		pt.Subscribe(timer.NotificationObserver{Desktop: cfg.Notifications.Desktop, Bell: cfg.Notifications.Bell})
+		pt.Subscribe(timer.StorageObserver{Storage: storage})
+		pt.Subscribe(timer.GoalObserver{Goals: storage, Days: dayBoundary(), WeekStart: configWeekStart(), Desktop: cfg.Notifications.Desktop, Bell: cfg.Notifications.Bell})
+		defer timer.RemoveStateFile(statePath)
+
+		// quit through the timer on signals, so the interval in progress
+		// is saved as aborted and the terminal leaves raw mode, registered
+		// before anything can take a Ctrl+C
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		defer signal.Stop(signals)
+		done := make(chan struct{})
+		defer close(done)
+		go timer.QuitOnSignal(signals, pt.ControlChan, done)
 		go timer.ListenForCommands(pt.ControlChan)
 
 		defer keyboard.Close()
-		for {
-			ok := pt.Start()
-			if !ok {
-				pt.EndTime = time.Now()
-				storage.SaveTimerData(pt.WorkLabel, pt.StartTime, pt.EndTime)
+		if resume {
+			fmt.Println(color.CyanString("Resuming %s with %s left", checkpoint.Description, checkpoint.Clock()))
+			if ok := pt.Resume(checkpoint); !ok {
+				return
+			}
+		}
+		for pt.Start() {
+			if cycles > 0 && pt.Cycle >= cycles {
+				fmt.Println(color.GreenString("🎉 All %d cycles completed!", cycles))
 				return
 			}
 		}
 	},
 }
 
+// fill in the settings that were not given as flags from the preset, if one
+// is named, or else from the config file
+func resolveStartSettings(cmd *cobra.Command, args []string) error {
+	name := preset
+	if len(args) > 0 {
+		if name != "" && name != args[0] {
+			return fmt.Errorf("both preset %q and --preset %q given", args[0], name)
+		}
+		name = args[0]
+	}
+
+	settings := config.Preset{
+		Label:     cfg.Label,
+		Work:      cfg.Work,
+		Break:     cfg.Break,
+		LongBreak: &cfg.LongBreak,
+		LongEvery: &cfg.LongEvery,
+		Cycles:    &cfg.Cycles,
+	}
+	if name != "" {
+		p, err := cfg.Preset(name)
+		if err != nil {
+			return err
+		}
+		settings = p
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("label") {
+		label = settings.Label
+	}
+	if !flags.Changed("minutes") {
+		minutes = settings.Work
+	}
+	if !flags.Changed("break") {
+		breakMinutes = settings.Break
+	}
+	if !flags.Changed("long-break") {
+		longBreakMinutes = *settings.LongBreak
+	}
+	if !flags.Changed("long-every") {
+		longEvery = *settings.LongEvery
+	}
+	if !flags.Changed("cycles") {
+		cycles = *settings.Cycles
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(startCmd)
 
 	startCmd.Flags().StringVarP(&label, "label", "l", "Work", "label for the work session")
 	startCmd.Flags().IntVarP(&minutes, "minutes", "m", 30, "minutes to work")
 	startCmd.Flags().IntVarP(&breakMinutes, "break", "b", 5, "minutes to take a break")
+	startCmd.Flags().IntVar(&longBreakMinutes, "long-break", 0, "minutes of the long break (0 disables long breaks)")
+	startCmd.Flags().IntVar(&longEvery, "long-every", 4, "number of completed work intervals before a long break")
+	startCmd.Flags().IntVar(&cycles, "cycles", 0, "number of work intervals to complete before stopping (0 runs until quit)")
+	startCmd.Flags().StringVarP(&preset, "preset", "p", "", "named preset from the config file")
+	startCmd.Flags().BoolVarP(&inDaemon, "daemon", "d", false, "run the timer in the background daemon")
+	startCmd.Flags().BoolVar(&resume, "resume", false, "resume a timer that was interrupted by a crash or reboot")
+	startCmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "tag to attach to every session, can be repeated")
 }
@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -17,10 +18,12 @@ import (
 /*
 TIMEFRAME possible values:
 - all
-- today
-- week
-- month
-- year
+- today, yesterday
+- week, last-week
+- month, last-month
+- year, last-year
+- last-7d, last-30d, last-2w, ...
+- 2025-09-17, 2025-09, 2025
 */
 
 // statCmd represents the stat command
@@ -29,14 +32,51 @@ var statCmd = &cobra.Command{
 	Short: "Displays the statistics for the timeframe specified",
 	Long: `Displays the statistics for the timeframe specified. Possible values for timeframe are:
 - all
-- today
-- week
-- month
-- year`,
+- today, yesterday
+- week, last-week (weeks start on week_start from the config file)
+- month, last-month
+- year, last-year
+- last-Nd or last-Nw, the last N days or weeks including today, e.g. last-7d
+- a day, month or year, e.g. 2025-09-17, 2025-09 or 2025
+
+--from and --to give an arbitrary range instead, in the formats of 'pomo add';
+a bare date for --to includes that whole day. Days start at day_start in the
+timezone of the config file, like those of goals and streaks.
+
+The progress of the goals and the streaks of consecutive days are shown
+whatever the timeframe. A day keeps a streak going when it reaches the
+daily goal of the label, or else has streaks.min_pomodoros completed
+pomodoros; timezone and day_start in the config file decide when days
+start.
+
+--by hour or --by weekday charts the focused time and the completed
+pomodoros by hour of the day or by day of the week. Sessions that run
+across hours or days are split by their focused time in each.
+
+--compare compares every total and every label with the previous period
+of the same length: the previous month for a month, the week before for a
+week.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		timeframe, _ := cmd.Flags().GetString("timeframe")
+		timeframe, err := statTimeFrame(cmd, time.Now())
+		if err != nil {
+			fmt.Println(color.RedString("❌ Error: %v", err))
+			return
+		}
+		by, _ := cmd.Flags().GetString("by")
+		if by != "" && by != string(timer.ByHour) && by != string(timer.ByWeekday) {
+			fmt.Println(color.RedString("❌ Error: unknown --by %q, use hour or weekday", by))
+			return
+		}
+		var previous timer.TimeFrame
+		compare, _ := cmd.Flags().GetBool("compare")
+		if compare {
+			if previous, err = timeframe.Previous(); err != nil {
+				fmt.Println(color.RedString("❌ Error: %v", err))
+				return
+			}
+		}
 
-		storage, err := timer.NewSQLiteStorage("./pomodoro.db")
+		storage, err := openStorage()
 		if err != nil {
 			fmt.Println(color.RedString("❌ Error opening database: %v", err))
 			return
@@ -48,44 +88,103 @@ var statCmd = &cobra.Command{
 			fmt.Println(color.RedString("❌ Error computing stats: %v", err))
 			return
 		}
+		pomoStats.Goals, err = storage.GoalProgress(time.Now(), dayBoundary(), configWeekStart())
+		if err != nil {
+			fmt.Println(color.RedString("❌ Error reading goals: %v", err))
+			return
+		}
+		pomoStats.Streaks, err = storage.ComputeStreaks(streakOptions(), time.Now())
+		if err != nil {
+			fmt.Println(color.RedString("❌ Error computing streaks: %v", err))
+			return
+		}
+		if by != "" {
+			opts := timer.DistributionOptions{By: timer.Bucketing(by), Boundary: dayBoundary(), WeekStart: configWeekStart()}
+			pomoStats.Distribution, err = storage.ComputeDistribution(timeframe, opts)
+			if err != nil {
+				fmt.Println(color.RedString("❌ Error computing the distribution: %v", err))
+				return
+			}
+		}
+		var comparison *timer.Comparison
+		if compare {
+			previousStats, err := storage.ComputePomoStats(previous)
+			if err != nil {
+				fmt.Println(color.RedString("❌ Error computing stats: %v", err))
+				return
+			}
+			comparison = timer.Compare(pomoStats, previousStats)
+		}
+		if machineOutput() {
+			if comparison != nil {
+				err = writeComparison(comparison)
+			} else {
+				err = writeStats(pomoStats)
+			}
+			if err != nil {
+				fmt.Println(color.RedString("❌ Error: %v", err))
+			}
+			return
+		}
 
 		// Headline
-		fmt.Println(color.CyanString("\n📊 Pomodoro Statistics (%s)\n", timeframe))
-
-		// Tabwriter for aligned columns
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-
-		// Summary stats
-		fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Total Sessions:"), fmt.Sprintf("%d", pomoStats.TotalSessions))
-		fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Total Work Duration:"), pomoStats.TotalWorkDuration.String())
-		fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Average Session:"), pomoStats.AverageSessionDuration.String())
-		fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Longest Session:"), pomoStats.LongestSession.String())
-		fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Shortest Session:"), pomoStats.ShortestSession.String())
-		w.Flush()
-
-		fmt.Println()
+		if comparison != nil {
+			fmt.Println(color.CyanString("\n📊 Pomodoro Statistics: %s compared with %s\n", timeframe.String(), previous.String()))
+			printComparison(comparison)
+		} else {
+			fmt.Println(color.CyanString("\n📊 Pomodoro Statistics: %s\n", timeframe.String()))
+			printSummary(pomoStats)
+		}
 
-		// Sessions per label
-		if len(pomoStats.PomosPerLabel) > 0 {
-			fmt.Println(color.GreenString("📌 Sessions per Label:"))
-			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			for label, count := range pomoStats.PomosPerLabel {
-				fmt.Fprintf(w, "  %s\t%d\n", color.MagentaString(label), count)
+		// Share of the planned duration achieved per label
+		if len(pomoStats.PlannedAchievedPerLabel) > 0 {
+			fmt.Println(color.GreenString("🎯 Planned Duration Achieved per Label:"))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for label, achieved := range pomoStats.PlannedAchievedPerLabel {
+				fmt.Fprintf(w, "  %s\t%s\n", color.MagentaString(label), formatPercent(achieved))
 			}
 			w.Flush()
 			fmt.Println()
 		}
 
-		// Time spent per label
-		if len(pomoStats.TimeSpentPerLabel) > 0 {
-			fmt.Println(color.GreenString("⏱ Time Spent per Label:"))
-			w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-			for label, dur := range pomoStats.TimeSpentPerLabel {
-				fmt.Fprintf(w, "  %s\t%s\n", color.MagentaString(label), formatDuration(dur))
+		// Progress of the goals today and this week
+		if len(pomoStats.Goals) > 0 {
+			printGoals(pomoStats.Goals)
+			fmt.Println()
+		}
+
+		// Streaks of consecutive days, up to today
+		// labels that never had a qualifying day are left out
+		var streaks []timer.Streak
+		for _, streak := range pomoStats.Streaks {
+			if streak.Longest > 0 {
+				streaks = append(streaks, streak)
+			}
+		}
+		if len(streaks) > 0 {
+			fmt.Println(color.GreenString("🔥 Streaks:"))
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, streak := range streaks {
+				label := streak.Label
+				if label == "" {
+					label = "All labels"
+				}
+				current := color.HiBlackString("none")
+				if streak.Current > 0 {
+					current = fmt.Sprintf("%s since %s", formatDays(streak.Current), streak.CurrentStart.Format("2006-01-02"))
+				}
+				fmt.Fprintf(w, "  %s\tcurrent %s\tlongest %s (%s to %s)\n", color.MagentaString(label), current,
+					formatDays(streak.Longest), streak.LongestStart.Format("2006-01-02"), streak.LongestEnd.Format("2006-01-02"))
 			}
 			w.Flush()
 			fmt.Println()
 		}
+
+		// Focused time by hour or weekday
+		if pomoStats.Distribution != nil {
+			printDistribution(pomoStats.Distribution)
+			fmt.Println()
+		}
 	},
 }
 
@@ -101,7 +200,235 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// statCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	statCmd.Flags().StringP("timeframe", "t", "all", "timeframe for stats")
+	statCmd.Flags().StringP("timeframe", "t", "all", "timeframe for stats, e.g. week, last-7d or 2025-09")
+	statCmd.Flags().String("from", "", "start of a custom range, e.g. 2025-09-01")
+	statCmd.Flags().String("to", "", "end of a custom range, e.g. 2025-09-30")
+	statCmd.Flags().Bool("compare", false, "compare with the previous period of the same length")
+	statCmd.Flags().String("by", "", "also chart the focused time by hour or by weekday")
+}
+
+// resolve the time frame of the stat command from --timeframe, or from
+// --from and --to
+func statTimeFrame(cmd *cobra.Command, now time.Time) (timer.TimeFrame, error) {
+	flags := cmd.Flags()
+	spec, _ := flags.GetString("timeframe")
+	from, _ := flags.GetString("from")
+	to, _ := flags.GetString("to")
+
+	if from == "" && to == "" {
+		return timer.ParseTimeFrame(spec, now, dayBoundary(), configWeekStart())
+	}
+	if flags.Changed("timeframe") {
+		return timer.TimeFrame{}, fmt.Errorf("give either --timeframe or --from/--to")
+	}
+	return timer.ParseDateRange(from, to, now, dayBoundary())
+}
+
+// print the totals of the statistics and the sessions and time per label
+func printSummary(pomoStats *timer.PomoStats) {
+	// Tabwriter for aligned columns
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	// Summary stats
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Total Sessions:"), fmt.Sprintf("%d", pomoStats.TotalSessions))
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Total Work Duration:"), pomoStats.TotalWorkDuration.String())
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Total Break Duration:"), pomoStats.TotalBreakDuration.String())
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Average Session:"), pomoStats.AverageSessionDuration.String())
+	longest := pomoStats.LongestSession.String()
+	if pomoStats.LongestSessionLabel != "" {
+		longest += " (" + color.MagentaString(pomoStats.LongestSessionLabel) + ")"
+	}
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Longest Session:"), longest)
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Shortest Session:"), pomoStats.ShortestSession.String())
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Median Session:"), pomoStats.MedianSession.String())
+	if p := pomoStats.SessionPercentiles; len(p) > 0 {
+		fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Session Percentiles:"),
+			fmt.Sprintf("p25 %s, p75 %s, p90 %s", p[25], p[75], p[90]))
+	}
+	fmt.Fprintf(w, "%s\t%s\n", color.YellowString("Completion Rate:"), formatPercent(pomoStats.CompletionRate))
+	w.Flush()
+
+	fmt.Println()
+
+	// Sessions per label
+	if len(pomoStats.PomosPerLabel) > 0 {
+		fmt.Println(color.GreenString("📌 Sessions per Label:"))
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for label, count := range pomoStats.PomosPerLabel {
+			fmt.Fprintf(w, "  %s\t%d\n", color.MagentaString(label), count)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	// Time spent per label
+	if len(pomoStats.TimeSpentPerLabel) > 0 {
+		fmt.Println(color.GreenString("⏱ Time Spent per Label:"))
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for label, dur := range pomoStats.TimeSpentPerLabel {
+			fmt.Fprintf(w, "  %s\t%s\n", color.MagentaString(label), formatDuration(dur))
+		}
+		w.Flush()
+		fmt.Println()
+	}
+}
+
+// metricNames are the headings of the metrics of a comparison
+var metricNames = map[string]string{
+	"total_sessions":   "Total Sessions",
+	"total_work":       "Total Work Duration",
+	"total_break":      "Total Break Duration",
+	"average_session":  "Average Session",
+	"longest_session":  "Longest Session",
+	"shortest_session": "Shortest Session",
+	"median_session":   "Median Session",
+	"completion_rate":  "Completion Rate",
+	"sessions":         "Sessions",
+	"work":             "Time Spent",
+}
+
+// print every metric of both periods with the change between them, the
+// totals first and then the metrics per label
+func printComparison(c *timer.Comparison) {
+	headers := []string{"Metric", "This Period", "Previous", "Change", "%"}
+	var totals, perLabel [][]string
+	for _, d := range c.Deltas {
+		name := color.YellowString(metricNames[d.Metric])
+		if d.Label != "" {
+			name = color.MagentaString(d.Label) + " " + metricNames[d.Metric]
+		}
+		row := []string{name, formatDeltaValue(d.Unit, d.Current), formatDeltaValue(d.Unit, d.Previous), formatChange(d), formatChangePercent(d)}
+		if d.Label == "" {
+			totals = append(totals, row)
+		} else {
+			perLabel = append(perLabel, row)
+		}
+	}
+	printTable(headers, totals)
+	fmt.Println()
+	if len(perLabel) > 0 {
+		fmt.Println(color.GreenString("📌 Per Label:"))
+		printTable(headers, perLabel)
+		fmt.Println()
+	}
+}
+
+func formatDeltaValue(unit timer.DeltaUnit, value float64) string {
+	switch unit {
+	case timer.UnitSeconds:
+		return formatDuration(time.Duration(value * float64(time.Second)))
+	case timer.UnitRatio:
+		return formatPercent(value)
+	}
+	return fmt.Sprintf("%.0f", value)
+}
+
+// the change with an arrow, in percentage points for ratios
+func formatChange(d timer.Delta) string {
+	change := d.Change()
+	magnitude := change
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+	value := formatDeltaValue(d.Unit, magnitude)
+	if d.Unit == timer.UnitRatio {
+		value = fmt.Sprintf("%.1f pts", magnitude*100)
+	}
+	return arrow(change, value)
+}
+
+// the change relative to the previous period, "new" for what was not there
+func formatChangePercent(d timer.Delta) string {
+	percent, ok := d.Percent()
+	if !ok {
+		if d.Current == 0 {
+			return color.HiBlackString("–")
+		}
+		return color.GreenString("new")
+	}
+	magnitude := percent
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+	return arrow(percent, formatPercent(magnitude))
+}
+
+// mark a change as up in green, down in red or unchanged
+func arrow(change float64, value string) string {
+	switch {
+	case change > 0:
+		return color.GreenString("▲ %s", value)
+	case change < 0:
+		return color.RedString("▼ %s", value)
+	}
+	return color.HiBlackString("= %s", value)
+}
+
+// chart the focused time of every bucket as a horizontal bar, leaving out
+// the hours before the first and after the last one with work
+func printDistribution(dist *timer.Distribution) {
+	buckets := dist.Buckets
+	title := "⏰ Focus by Hour:"
+	if dist.By == timer.ByWeekday {
+		title = "📆 Focus by Weekday:"
+	} else {
+		for len(buckets) > 0 && buckets[0].Worked == 0 {
+			buckets = buckets[1:]
+		}
+		for len(buckets) > 0 && buckets[len(buckets)-1].Worked == 0 {
+			buckets = buckets[:len(buckets)-1]
+		}
+	}
+	if len(buckets) == 0 {
+		return
+	}
+
+	fmt.Println(color.GreenString(title))
+	most := dist.Most()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, b := range buckets {
+		fraction := 0.0
+		if most > 0 {
+			fraction = float64(b.Worked) / float64(most)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%.1f pomodoros\n", color.MagentaString(b.Name),
+			color.CyanString(bar(fraction, 30)), formatDuration(b.Worked), b.Pomodoros)
+	}
+	w.Flush()
+}
+
+// draw a bar of up to width cells filled to fraction, in eighths of a cell
+func bar(fraction float64, width int) string {
+	eighths := int(fraction*float64(width*8) + 0.5)
+	partial := []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}[eighths%8]
+	s := strings.Repeat("█", eighths/8) + partial
+	return s + strings.Repeat(" ", width-eighths/8-len([]rune(partial)))
+}
+
+// the streak rules from the config file
+func streakOptions() timer.StreakOptions {
+	return timer.StreakOptions{Boundary: dayBoundary(), MinPomodoros: cfg.Streaks.MinPomodoros}
+}
+
+// the first day of the week from the config file
+func configWeekStart() time.Weekday {
+	// the week start was checked when the config was loaded
+	weekStart, _ := parseWeekStart(cfg.WeekStart)
+	return weekStart
+}
+
+// the time zone and hour days start at from the config file
+func dayBoundary() timer.DayBoundary {
+	// the time zone was checked when the config was loaded
+	loc, _ := cfg.Location()
+	return timer.DayBoundary{Location: loc, Hour: cfg.DayStart}
+}
+
+func formatDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
 }
 
 func formatDuration(d time.Duration) string {
@@ -112,4 +439,8 @@ func formatDuration(d time.Duration) string {
 		return fmt.Sprintf("%02dh %02dm %02ds", h, m, s)
 	}
 	return fmt.Sprintf("%02dm %02ds", m, s)
-}
\ No newline at end of file
+}
+
+func formatPercent(ratio float64) string {
+	return fmt.Sprintf("%.1f%%", ratio*100)
+}
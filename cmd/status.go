@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Dima-salang/pomolite/daemon"
+	"github.com/Dima-salang/pomolite/timer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the running timer",
+	Long: `Show the running timer.
+
+	The state is read from the daemon when it is running, and otherwise from
+	the state file written by 'pomo start'.
+
+	FORMATS:
+	json   : the full state as JSON
+	waybar : JSON for a waybar custom module
+	tmux   : a short string with tmux color codes for status-right
+	any other value is a Go text/template with the fields .Label, .Phase,
+	.Description, .Remaining, .RemainingSeconds, .Cycle, .Position,
+	.Percent, .Paused and .Running, e.g. '{{.Label}} {{.Remaining}}', and
+	.Goals, the goals with .Goal.Name, .Progress and .Done for each
+
+	Every format but tmux also shows the progress of the goals set with
+	'pomo goal set'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+
+		state, err := readTimerState()
+		if err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+			return
+		}
+
+		// goals are extra, a database that cannot be read must not break
+		// the status line
+		goals, _ := readGoalProgress()
+
+		if format == "" {
+			printState(state)
+			printGoals(goals)
+			return
+		}
+		if err := timer.WriteStatus(os.Stdout, format, state, goals); err != nil {
+			fmt.Println(color.RedString("Error: %v", err))
+		}
+	},
+}
+
+// ask the daemon first and fall back to the state file of a foreground timer
+func readTimerState() (timer.State, error) {
+	resp, err := daemon.Send(socketPath, daemon.Request{Command: daemon.CommandStatus})
+	if err == nil {
+		if resp.State.Running {
+			return *resp.State, nil
+		}
+	} else if !errors.Is(err, daemon.ErrNotRunning) {
+		return timer.State{}, err
+	}
+	return timer.ReadLiveState(timer.DefaultStatePath())
+}
+
+// read the progress of the goals from the database
+func readGoalProgress() ([]timer.GoalProgress, error) {
+	storage, err := openStorage()
+	if err != nil {
+		return nil, err
+	}
+	defer storage.Close()
+	return storage.GoalProgress(time.Now(), dayBoundary(), configWeekStart())
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringP("format", "f", "", "output format: json, waybar, tmux or a Go template")
+}
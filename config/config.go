@@ -0,0 +1,253 @@
+package config
+
+// Configuration file with defaults and named presets for pomo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the defaults read from the configuration file. Durations are
+// in minutes, like the flags of `pomo start`.
+type Config struct {
+	Label     string `yaml:"label"`
+	Work      int    `yaml:"work"`
+	Break     int    `yaml:"break"`
+	LongBreak int    `yaml:"long_break"`
+	LongEvery int    `yaml:"long_every"`
+	// Cycles stops the timer after that many work intervals, 0 runs it
+	// until it is quit.
+	Cycles int `yaml:"cycles"`
+
+	Database      string             `yaml:"database"`
+	Notifications Notifications      `yaml:"notifications"`
+	Colors        bool               `yaml:"colors"`
+	Presets       map[string]*Preset `yaml:"presets"`
+
+	// Timezone names the zone days are counted in, e.g. Europe/Berlin, and
+	// is the local one when empty. DayStart is the hour days start at, so
+	// that work past midnight can count toward the evening before.
+	Timezone string `yaml:"timezone"`
+	DayStart int    `yaml:"day_start"`
+	// WeekStart is the first day of the week in the heatmap, monday or
+	// sunday.
+	WeekStart string  `yaml:"week_start"`
+	Streaks   Streaks `yaml:"streaks"`
+}
+
+type Notifications struct {
+	Desktop bool `yaml:"desktop"`
+	Bell    bool `yaml:"bell"`
+}
+
+// Streaks decide which days keep a streak going when the label has no
+// daily goal.
+type Streaks struct {
+	MinPomodoros int `yaml:"min_pomodoros"`
+}
+
+// Preset bundles the settings of `pomo start` under a name. Fields left out
+// fall back to the defaults of the configuration. LongBreak, LongEvery and
+// Cycles are pointers, so that a preset can set them to 0, e.g. to disable
+// long breaks.
+type Preset struct {
+	Label     string `yaml:"label"`
+	Work      int    `yaml:"work"`
+	Break     int    `yaml:"break"`
+	LongBreak *int   `yaml:"long_break"`
+	LongEvery *int   `yaml:"long_every"`
+	Cycles    *int   `yaml:"cycles"`
+}
+
+// Default is the configuration used when there is no configuration file.
+func Default() *Config {
+	return &Config{
+		Label:     "Work",
+		Work:      30,
+		Break:     5,
+		LongBreak: 0,
+		LongEvery: 4,
+		Notifications: Notifications{
+			Desktop: true,
+			Bell:    true,
+		},
+		Colors:    true,
+		Presets:   map[string]*Preset{},
+		WeekStart: "monday",
+		Streaks: Streaks{
+			MinPomodoros: 1,
+		},
+	}
+}
+
+// DefaultPath is config.yaml in $XDG_CONFIG_HOME/pomolite, falling back to
+// ~/.config/pomolite.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "pomolite.yaml"
+	}
+	return filepath.Join(dir, "pomolite", "config.yaml")
+}
+
+// DefaultDatabasePath is pomodoro.db in $XDG_DATA_HOME/pomolite, falling
+// back to ~/.local/share/pomolite.
+func DefaultDatabasePath() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "pomodoro.db"
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "pomolite", "pomodoro.db")
+}
+
+// DatabasePath resolves the database to use: the --db flag, then the
+// POMOLITE_DB environment variable, then the config file, then the default.
+func (c *Config) DatabasePath(flag string) string {
+	if flag != "" {
+		return expandHome(flag)
+	}
+	if env := os.Getenv("POMOLITE_DB"); env != "" {
+		return expandHome(env)
+	}
+	if c.Database != "" {
+		return c.Database
+	}
+	return DefaultDatabasePath()
+}
+
+// Location is the time zone days are counted in.
+func (c *Config) Location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// Load reads the configuration file at path on top of the defaults. A
+// missing file is only an error when it was asked for explicitly.
+func Load(path string, explicit bool) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Database = expandHome(cfg.Database)
+	// a relative database is next to the config file, not wherever pomo
+	// happens to run
+	if cfg.Database != "" && !filepath.IsAbs(cfg.Database) {
+		dir, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return nil, err
+		}
+		cfg.Database = filepath.Join(dir, cfg.Database)
+	}
+	return cfg, nil
+}
+
+// expandHome replaces a leading ~/ with the home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// Preset returns the named preset with every unset field filled in from
+// the configuration defaults, so none of its pointers are nil.
+func (c *Config) Preset(name string) (Preset, error) {
+	p, ok := c.Presets[name]
+	if !ok || p == nil {
+		return Preset{}, fmt.Errorf("unknown preset %q, known presets: %v", name, c.PresetNames())
+	}
+
+	preset := *p
+	if preset.Label == "" {
+		preset.Label = name
+	}
+	if preset.Work == 0 {
+		preset.Work = c.Work
+	}
+	if preset.Break == 0 {
+		preset.Break = c.Break
+	}
+	if preset.LongBreak == nil {
+		preset.LongBreak = &c.LongBreak
+	}
+	if preset.LongEvery == nil {
+		preset.LongEvery = &c.LongEvery
+	}
+	if preset.Cycles == nil {
+		preset.Cycles = &c.Cycles
+	}
+	return preset, nil
+}
+
+// PresetNames lists the presets in alphabetical order.
+func (c *Config) PresetNames() []string {
+	names := make([]string, 0, len(c.Presets))
+	for name := range c.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Config) validate() error {
+	if c.Work <= 0 || c.Break <= 0 {
+		return fmt.Errorf("work and break must be greater than 0")
+	}
+	if c.LongBreak < 0 || c.LongEvery < 0 || c.Cycles < 0 {
+		return fmt.Errorf("long_break, long_every and cycles must not be negative")
+	}
+	if c.DayStart < 0 || c.DayStart > 23 {
+		return fmt.Errorf("day_start must be an hour from 0 to 23")
+	}
+	if c.WeekStart != "monday" && c.WeekStart != "sunday" {
+		return fmt.Errorf("week_start must be monday or sunday")
+	}
+	if c.Streaks.MinPomodoros < 1 {
+		return fmt.Errorf("streaks.min_pomodoros must be at least 1, a day without a completed pomodoro never counts")
+	}
+	if _, err := c.Location(); err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+	for name, p := range c.Presets {
+		if p == nil {
+			continue
+		}
+		if p.Work < 0 || p.Break < 0 || negative(p.LongBreak) || negative(p.LongEvery) || negative(p.Cycles) {
+			return fmt.Errorf("preset %q: durations and counts must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// negative reports whether an optional setting is set and below 0
+func negative(n *int) bool {
+	return n != nil && *n < 0
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dima-salang/pomolite/config"
+)
+
+func loadConfig(t *testing.T, yaml string) *config.Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestPresetZeroIsNotUnset(t *testing.T) {
+	cfg := loadConfig(t, `
+long_break: 15
+long_every: 4
+cycles: 8
+presets:
+  sprint:
+    work: 25
+    long_break: 0
+    cycles: 0
+`)
+
+	p, err := cfg.Preset("sprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Label != "sprint" || p.Work != 25 || p.Break != cfg.Break {
+		t.Errorf("expected the label, work and break of the preset or the config, got %+v", p)
+	}
+	if *p.LongBreak != 0 || *p.Cycles != 0 {
+		t.Errorf("expected the preset to disable long breaks and cycles, got %d and %d", *p.LongBreak, *p.Cycles)
+	}
+	if *p.LongEvery != 4 {
+		t.Errorf("expected long_every from the config, got %d", *p.LongEvery)
+	}
+}
+
+func TestPresetRejectsNegativeCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("presets:\n  broken:\n    cycles: -1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(path, true); err == nil {
+		t.Error("expected an error for a negative number of cycles")
+	}
+}
+
+func TestDatabasePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("database: sessions/pomodoro.db\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	withDatabase, err := config.Load(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withoutDatabase := config.Default()
+
+	cases := []struct {
+		name string
+		cfg  *config.Config
+		flag string
+		env  string
+		want string
+	}{
+		{"flag over everything", withDatabase, "~/flag.db", "/env.db", filepath.Join(home, "flag.db")},
+		{"environment over the config file", withDatabase, "", "~/env.db", filepath.Join(home, "env.db")},
+		{"config file relative to its directory", withDatabase, "", "", filepath.Join(dir, "sessions", "pomodoro.db")},
+		{"XDG data directory by default", withoutDatabase, "", "", filepath.Join(home, "data", "pomolite", "pomodoro.db")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv("POMOLITE_DB", c.env)
+			if got := c.cfg.DatabasePath(c.flag); got != c.want {
+				t.Errorf("expected %s, got %s", c.want, got)
+			}
+		})
+	}
+}
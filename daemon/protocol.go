@@ -0,0 +1,77 @@
+/*
+Package daemon runs Pomodoro timers in the background and controls them over
+a Unix domain socket.
+
+# Protocol
+
+Clients connect to the socket and exchange line-delimited JSON: every
+request is a single JSON object followed by a newline, and the daemon answers
+each request with exactly one JSON object on its own line. A connection may
+carry any number of requests.
+
+Requests name a command and, for "start", the timer settings:
+
+	{"command":"start","label":"Coding","work_minutes":25,"break_minutes":5,"long_break_minutes":20,"long_every":4,"cycles":8,"tags":["client-a"]}
+	{"command":"pause"}
+	{"command":"resume"}
+	{"command":"skip"}
+	{"command":"stop"}
+	{"command":"status"}
+
+Responses report whether the command succeeded and the timer state after it
+was applied:
+
+	{"ok":true,"state":{"running":true,"paused":false,"label":"Coding","phase":"work",...}}
+	{"ok":false,"error":"no timer is running"}
+
+"cycles" stops the timer after that many work intervals and may be left out
+to run it until it is stopped. "start" fails while a timer is running, and
+every command but "start" and "status" fails while none is. "stop" aborts
+the running interval and saves it like quitting `pomo start` does.
+*/
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+const (
+	CommandStart  = "start"
+	CommandPause  = "pause"
+	CommandResume = "resume"
+	CommandSkip   = "skip"
+	CommandStop   = "stop"
+	CommandStatus = "status"
+)
+
+// Request is a single command sent to the daemon.
+type Request struct {
+	Command          string   `json:"command"`
+	Label            string   `json:"label,omitempty"`
+	WorkMinutes      int      `json:"work_minutes,omitempty"`
+	BreakMinutes     int      `json:"break_minutes,omitempty"`
+	LongBreakMinutes int      `json:"long_break_minutes,omitempty"`
+	LongEvery        int      `json:"long_every,omitempty"`
+	Cycles           int      `json:"cycles,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+}
+
+// Response is the daemon's answer to a Request.
+type Response struct {
+	OK    bool         `json:"ok"`
+	Error string       `json:"error,omitempty"`
+	State *timer.State `json:"state,omitempty"`
+}
+
+// DefaultSocketPath is the socket in $XDG_RUNTIME_DIR, or in the temporary
+// directory when that is not set.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pomolite.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("pomolite-%d.sock", os.Getuid()))
+}
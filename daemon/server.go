@@ -0,0 +1,240 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+// Server runs at most one timer at a time and serves the control protocol
+// on a Unix domain socket.
+type Server struct {
+	SocketPath string
+	Storage    timer.Storage
+	// Observers are subscribed to every timer the server starts, after the
+	// state tracker and before the storage observer.
+	Observers []timer.Observer
+	// AfterSave are subscribed after the storage observer, for observers
+	// that read the saved sessions back.
+	AfterSave []timer.Observer
+	// Clock is the time source of the timers, the system clock when nil.
+	Clock timer.Clock
+
+	mu       sync.Mutex
+	listener net.Listener
+	pt       *timer.PomodoroTimer
+	tracker  *timer.StateTracker
+	done     chan struct{}
+}
+
+// ListenAndServe listens on the socket and handles connections until Close
+// is called. A socket file left behind by a daemon that is no longer running
+// is replaced.
+func (s *Server) ListenAndServe() error {
+	if _, err := os.Stat(s.SocketPath); err == nil {
+		if conn, err := net.Dial("unix", s.SocketPath); err == nil {
+			conn.Close()
+			return fmt.Errorf("a daemon is already listening on %s", s.SocketPath)
+		}
+		if err := os.Remove(s.SocketPath); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", s.SocketPath)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the running timer, saving its interval as aborted, and stops
+// listening.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running() {
+		s.stop()
+	}
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp = Response{Error: fmt.Sprintf("invalid request: %v", err)}
+		} else {
+			resp = s.Handle(req)
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+// Handle applies a single request and reports the resulting timer state.
+func (s *Server) Handle(req Request) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	switch req.Command {
+	case CommandStatus:
+	case CommandStart:
+		err = s.start(req)
+	case CommandPause, CommandResume, CommandSkip:
+		err = s.control(req.Command)
+	case CommandStop:
+		err = s.stop()
+	default:
+		err = fmt.Errorf("unknown command: %q", req.Command)
+	}
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+
+	state := timer.State{}
+	if s.tracker != nil {
+		state = s.tracker.State()
+	}
+	// a timer that ran all its cycles is done without having been stopped
+	state.Running = state.Running && s.running()
+	return Response{OK: true, State: &state}
+}
+
+// running reports whether the timer goroutine is still alive; the caller
+// holds s.mu
+func (s *Server) running() bool {
+	if s.done == nil {
+		return false
+	}
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *Server) start(req Request) error {
+	if s.running() {
+		return errors.New("a timer is already running")
+	}
+	if req.WorkMinutes <= 0 || req.BreakMinutes <= 0 {
+		return errors.New("work and break minutes must be greater than 0")
+	}
+	if err := timer.ValidateLongBreak(req.LongBreakMinutes, req.LongEvery); err != nil {
+		return err
+	}
+	if req.Cycles < 0 {
+		return errors.New("cycles must not be negative")
+	}
+	if req.Label == "" {
+		req.Label = "Work"
+	}
+
+	var opts []timer.Option
+	if s.Clock != nil {
+		opts = append(opts, timer.WithClock(s.Clock))
+	}
+	pt := timer.NewPomodoroTimer(
+		time.Duration(req.WorkMinutes)*time.Minute,
+		time.Duration(req.BreakMinutes)*time.Minute,
+		req.Label,
+		opts...,
+	)
+	pt.LongBreakDuration = time.Duration(req.LongBreakMinutes) * time.Minute
+	pt.LongBreakEvery = req.LongEvery
+	pt.Tags = req.Tags
+
+	tracker := &timer.StateTracker{}
+	pt.Subscribe(tracker)
+	for _, observer := range s.Observers {
+		pt.Subscribe(observer)
+	}
+	if s.Storage != nil {
+		pt.Subscribe(timer.StorageObserver{Storage: s.Storage})
+	}
+	for _, observer := range s.AfterSave {
+		pt.Subscribe(observer)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for pt.Start() {
+			if req.Cycles > 0 && pt.Cycle >= req.Cycles {
+				return
+			}
+		}
+	}()
+
+	s.pt, s.tracker, s.done = pt, tracker, done
+	s.settle()
+	return nil
+}
+
+func (s *Server) control(command string) error {
+	if !s.running() {
+		return errors.New("no timer is running")
+	}
+	select {
+	case s.pt.ControlChan <- command:
+	case <-s.done:
+		return errors.New("no timer is running")
+	}
+	s.settle()
+	return nil
+}
+
+func (s *Server) stop() error {
+	if !s.running() {
+		return errors.New("no timer is running")
+	}
+	select {
+	case s.pt.ControlChan <- "quit":
+		<-s.done
+	case <-s.done:
+	}
+	return nil
+}
+
+// settle waits until the countdown is back to listening for commands, so the
+// events for the last command have been published by the time the state is
+// read. The countdown ignores the empty command.
+func (s *Server) settle() {
+	select {
+	case s.pt.ControlChan <- "":
+	case <-s.done:
+	}
+}
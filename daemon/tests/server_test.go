@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/daemon"
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+// start a server on a socket in a temporary directory, stopped at the end of
+// the test
+func newTestServer(t *testing.T) (*daemon.Server, *timer.SQLiteStorage, *timer.FakeClock) {
+	t.Helper()
+	// socket paths are limited to about 100 bytes, shorter than t.TempDir()
+	dir, err := os.MkdirTemp("", "pomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	storage, err := timer.NewSQLiteStorage(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	server := &daemon.Server{SocketPath: filepath.Join(dir, "pomo.sock"), Storage: storage, Clock: clock}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+	t.Cleanup(func() {
+		server.Close()
+		if err := <-served; err != nil {
+			t.Error(err)
+		}
+		storage.Close()
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := daemon.Send(server.SocketPath, daemon.Request{Command: daemon.CommandStatus})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, daemon.ErrNotRunning) || time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return server, storage, clock
+}
+
+// send a request that must succeed and return the state after it
+func send(t *testing.T, server *daemon.Server, req daemon.Request) timer.State {
+	t.Helper()
+	resp, err := daemon.Send(server.SocketPath, req)
+	if err != nil {
+		t.Fatalf("%s: %v", req.Command, err)
+	}
+	return *resp.State
+}
+
+func TestServerCommands(t *testing.T) {
+	server, storage, clock := newTestServer(t)
+
+	if state := send(t, server, daemon.Request{Command: daemon.CommandStatus}); state.Running {
+		t.Fatalf("expected no timer before start, got %+v", state)
+	}
+	if _, err := daemon.Send(server.SocketPath, daemon.Request{Command: daemon.CommandPause}); err == nil {
+		t.Error("expected pause to fail without a timer")
+	}
+
+	state := send(t, server, daemon.Request{Command: daemon.CommandStart, Label: "Coding", WorkMinutes: 25, BreakMinutes: 5})
+	if !state.Running || state.Phase != timer.KindWork || state.Remaining() != 25*time.Minute {
+		t.Fatalf("expected 25m of Coding to start, got %+v", state)
+	}
+	if _, err := daemon.Send(server.SocketPath, daemon.Request{Command: daemon.CommandStart, WorkMinutes: 25, BreakMinutes: 5}); err == nil {
+		t.Error("expected a second start to fail")
+	}
+
+	clock.Advance(10 * time.Minute)
+	if state := send(t, server, daemon.Request{Command: daemon.CommandStatus}); state.Remaining() != 15*time.Minute {
+		t.Errorf("expected 15m left after 10m, got %s", state.Remaining())
+	}
+
+	if state := send(t, server, daemon.Request{Command: daemon.CommandPause}); !state.Paused {
+		t.Errorf("expected the timer to pause, got %+v", state)
+	}
+	clock.Advance(3 * time.Minute)
+	state = send(t, server, daemon.Request{Command: daemon.CommandResume})
+	if state.Paused || state.Remaining() != 15*time.Minute {
+		t.Errorf("expected the timer to resume with 15m left, got %+v", state)
+	}
+
+	state = send(t, server, daemon.Request{Command: daemon.CommandSkip})
+	if state.Phase != timer.KindShortBreak || state.Remaining() != 5*time.Minute {
+		t.Errorf("expected skip to start the break, got %+v", state)
+	}
+
+	if state := send(t, server, daemon.Request{Command: daemon.CommandStop}); state.Running {
+		t.Errorf("expected stop to stop the timer, got %+v", state)
+	}
+	if _, err := daemon.Send(server.SocketPath, daemon.Request{Command: daemon.CommandStop}); err == nil {
+		t.Error("expected a second stop to fail")
+	}
+
+	sessions, err := storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected the work interval and the break to be saved, got %+v", sessions)
+	}
+	for _, session := range sessions {
+		switch session.Kind {
+		case timer.KindWork:
+			if session.Status != timer.StatusSkipped || session.Duration() != 13*time.Minute || session.NetDuration() != 10*time.Minute {
+				t.Errorf("expected 10m of skipped work with a 3m pause, got %+v", session)
+			}
+		case timer.KindShortBreak:
+			if session.Status != timer.StatusAborted {
+				t.Errorf("expected the stopped break to be aborted, got %+v", session)
+			}
+		}
+	}
+}
+
+func TestServerStopsAfterCycles(t *testing.T) {
+	server, storage, clock := newTestServer(t)
+
+	send(t, server, daemon.Request{Command: daemon.CommandStart, WorkMinutes: 25, BreakMinutes: 5, Cycles: 1})
+	clock.Advance(25 * time.Minute)
+	// pause and resume wait for the break to be counting down
+	send(t, server, daemon.Request{Command: daemon.CommandPause})
+	send(t, server, daemon.Request{Command: daemon.CommandResume})
+	clock.Advance(5 * time.Minute)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for send(t, server, daemon.Request{Command: daemon.CommandStatus}).Running {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the timer to stop after one cycle")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sessions, err := storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].Status != timer.StatusCompleted || sessions[1].Status != timer.StatusCompleted {
+		t.Errorf("expected a completed work interval and break, got %+v", sessions)
+	}
+}
+
+func TestServerConcurrentClients(t *testing.T) {
+	server, storage, _ := newTestServer(t)
+	const clients = 8
+
+	// count the clients whose request succeeds when all send it at once
+	race := func(req daemon.Request) int {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded := 0
+		for i := 0; i < clients; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := daemon.Send(server.SocketPath, req); err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		return succeeded
+	}
+
+	if n := race(daemon.Request{Command: daemon.CommandStart, WorkMinutes: 25, BreakMinutes: 5}); n != 1 {
+		t.Fatalf("expected exactly one start to succeed, got %d", n)
+	}
+	for _, command := range []string{daemon.CommandPause, daemon.CommandStatus, daemon.CommandResume} {
+		if n := race(daemon.Request{Command: command}); n != clients {
+			t.Errorf("expected every %s to succeed, got %d of %d", command, n, clients)
+		}
+	}
+	if state := send(t, server, daemon.Request{Command: daemon.CommandStatus}); !state.Running || state.Paused {
+		t.Errorf("expected the timer to run after the resumes, got %+v", state)
+	}
+	if n := race(daemon.Request{Command: daemon.CommandStop}); n != 1 {
+		t.Errorf("expected exactly one stop to succeed, got %d", n)
+	}
+
+	sessions, err := storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Status != timer.StatusAborted {
+		t.Errorf("expected the work interval to be saved once as aborted, got %+v", sessions)
+	}
+}
+
+func TestServerRejectsLongBreakWithoutInterval(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	_, err := daemon.Send(server.SocketPath, daemon.Request{Command: daemon.CommandStart, WorkMinutes: 25, BreakMinutes: 5, LongBreakMinutes: 15})
+	want := timer.ValidateLongBreak(15, 0)
+	if err == nil || want == nil || err.Error() != want.Error() {
+		t.Errorf("expected %v, got %v", want, err)
+	}
+	if state := send(t, server, daemon.Request{Command: daemon.CommandStatus}); state.Running {
+		t.Errorf("expected no timer to start, got %+v", state)
+	}
+}
@@ -0,0 +1,194 @@
+package timer
+
+// Clock abstraction so the countdown can run on real or simulated time
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the timer.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks on C at a fixed period until stopped.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock is the Clock backed by the time package.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// FakeClock is a Clock that only moves when Advance is called. Ticks are
+// delivered synchronously, so a countdown driven by a FakeClock has consumed
+// every tick by the time Advance returns.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	tickers []*fakeTicker
+	afters  []*fakeAfter
+}
+
+type fakeTicker struct {
+	clock  *FakeClock
+	period time.Duration
+	next   time.Time
+	c      chan time.Time
+	done   chan struct{}
+}
+
+type fakeAfter struct {
+	deadline time.Time
+	c        chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{
+		clock:  c,
+		period: d,
+		next:   c.now.Add(d),
+		c:      make(chan time.Time),
+		done:   make(chan struct{}),
+	}
+	c.tickers = append(c.tickers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a := &fakeAfter{deadline: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.afters = append(c.afters, a)
+	c.cond.Broadcast()
+	return a.c
+}
+
+// Advance moves the clock forward by d, firing every tick and timer that
+// falls due on the way, in order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		due := c.nextDue(target)
+		if due.IsZero() {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		c.now = due
+
+		var tickers []*fakeTicker
+		for _, t := range c.tickers {
+			if !t.next.After(due) {
+				t.next = t.next.Add(t.period)
+				tickers = append(tickers, t)
+			}
+		}
+		afters := c.afters[:0]
+		for _, a := range c.afters {
+			if a.deadline.After(due) {
+				afters = append(afters, a)
+				continue
+			}
+			a.c <- due
+		}
+		c.afters = afters
+		c.mu.Unlock()
+
+		for _, t := range tickers {
+			select {
+			case t.c <- due:
+			case <-t.done:
+			}
+		}
+	}
+}
+
+// BlockUntil waits until at least n tickers or pending After calls are
+// waiting on the clock.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.tickers)+len(c.afters) < n {
+		c.cond.Wait()
+	}
+}
+
+// earliest tick or deadline no later than target, zero if there is none;
+// the caller holds c.mu
+func (c *FakeClock) nextDue(target time.Time) time.Time {
+	var due []time.Time
+	for _, t := range c.tickers {
+		due = append(due, t.next)
+	}
+	for _, a := range c.afters {
+		due = append(due, a.deadline)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].Before(due[j]) })
+	if len(due) == 0 || due[0].After(target) {
+		return time.Time{}
+	}
+	return due[0]
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.tickers {
+		if other == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			close(t.done)
+			c.cond.Broadcast()
+			return
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package timer
+
+// Statistics of a period against the one before
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// DeltaUnit is what the values of a Delta measure.
+type DeltaUnit string
+
+const (
+	UnitCount   DeltaUnit = "count"
+	UnitSeconds DeltaUnit = "seconds"
+	UnitRatio   DeltaUnit = "ratio"
+)
+
+// Delta is one metric in two periods, overall or for a label.
+type Delta struct {
+	Metric   string
+	Label    string
+	Unit     DeltaUnit
+	Current  float64
+	Previous float64
+}
+
+// Change is the difference from the previous period to the current one.
+func (d Delta) Change() float64 {
+	return d.Current - d.Previous
+}
+
+// Percent is the change relative to the previous period, and false when
+// there is nothing to compare with.
+func (d Delta) Percent() (float64, bool) {
+	if d.Previous == 0 {
+		return 0, false
+	}
+	return d.Change() / d.Previous, true
+}
+
+// Comparison is the statistics of a period next to those of the period of
+// the same length before it.
+type Comparison struct {
+	Current  *PomoStats `json:"current" yaml:"current"`
+	Previous *PomoStats `json:"previous" yaml:"previous"`
+	Deltas   []Delta    `json:"deltas" yaml:"deltas"`
+}
+
+// Compare lines up the metrics of two periods, the totals first and then
+// those of every label in either period, busiest label first.
+func Compare(current, previous *PomoStats) *Comparison {
+	c := &Comparison{Current: current, Previous: previous}
+	add := func(metric, label string, unit DeltaUnit, cur, prev float64) {
+		c.Deltas = append(c.Deltas, Delta{Metric: metric, Label: label, Unit: unit, Current: cur, Previous: prev})
+	}
+	secs := func(d time.Duration) float64 { return d.Seconds() }
+
+	add("total_sessions", "", UnitCount, float64(current.TotalSessions), float64(previous.TotalSessions))
+	add("total_work", "", UnitSeconds, secs(current.TotalWorkDuration), secs(previous.TotalWorkDuration))
+	add("total_break", "", UnitSeconds, secs(current.TotalBreakDuration), secs(previous.TotalBreakDuration))
+	add("average_session", "", UnitSeconds, secs(current.AverageSessionDuration), secs(previous.AverageSessionDuration))
+	add("longest_session", "", UnitSeconds, secs(current.LongestSession), secs(previous.LongestSession))
+	add("shortest_session", "", UnitSeconds, secs(current.ShortestSession), secs(previous.ShortestSession))
+	add("median_session", "", UnitSeconds, secs(current.MedianSession), secs(previous.MedianSession))
+	add("completion_rate", "", UnitRatio, current.CompletionRate, previous.CompletionRate)
+
+	// a session that crosses into a period counts toward its time but not
+	// its sessions, so a label can have one without the other
+	labels := make(map[string]bool)
+	for _, stats := range []*PomoStats{current, previous} {
+		for label := range stats.PomosPerLabel {
+			labels[label] = true
+		}
+		for label := range stats.TimeSpentPerLabel {
+			labels[label] = true
+		}
+	}
+	ordered := make([]string, 0, len(labels))
+	for label := range labels {
+		ordered = append(ordered, label)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		a, b := current.TimeSpentPerLabel[ordered[i]], current.TimeSpentPerLabel[ordered[j]]
+		if a != b {
+			return a > b
+		}
+		return ordered[i] < ordered[j]
+	})
+	for _, label := range ordered {
+		add("sessions", label, UnitCount, float64(current.PomosPerLabel[label]), float64(previous.PomosPerLabel[label]))
+		add("work", label, UnitSeconds, secs(current.TimeSpentPerLabel[label]), secs(previous.TimeSpentPerLabel[label]))
+	}
+	return c
+}
+
+type deltaRecord struct {
+	Metric   string   `json:"metric" yaml:"metric"`
+	Label    string   `json:"label" yaml:"label"`
+	Unit     string   `json:"unit" yaml:"unit"`
+	Current  float64  `json:"current" yaml:"current"`
+	Previous float64  `json:"previous" yaml:"previous"`
+	Change   float64  `json:"change" yaml:"change"`
+	Percent  *float64 `json:"percent" yaml:"percent"`
+}
+
+func (d Delta) record() deltaRecord {
+	r := deltaRecord{
+		Metric:   d.Metric,
+		Label:    d.Label,
+		Unit:     string(d.Unit),
+		Current:  d.Current,
+		Previous: d.Previous,
+		Change:   d.Change(),
+	}
+	if percent, ok := d.Percent(); ok {
+		r.Percent = &percent
+	}
+	return r
+}
+
+func (d Delta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.record())
+}
+
+func (d Delta) MarshalYAML() (any, error) {
+	return d.record(), nil
+}
@@ -0,0 +1,70 @@
+package timer
+
+// Focused time per day
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// DayTotal is the focused time of the work sessions that started on a day.
+type DayTotal struct {
+	Day    time.Time
+	Worked time.Duration
+}
+
+// DailyWorkTime sums the focused time of the work sessions that started in
+// the time frame per day, with days decided by boundary. An empty label
+// counts every label. Days without work are left out and the rest come in
+// order.
+func (s *SQLiteStorage) DailyWorkTime(timeframe TimeFrame, label string, boundary DayBoundary) ([]DayTotal, error) {
+	start, end := timeframe.bounds()
+	rows, err := s.db.Query(`
+		SELECT start_time, `+netDurationSQL+`
+		FROM sessions
+		WHERE kind = 'work' AND deleted_at IS NULL
+			AND (? = '' OR label = ?)
+			AND start_time >= ? AND start_time < ?
+	`, label, label, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	worked := make(map[time.Time]time.Duration)
+	for rows.Next() {
+		var startUnix, netSeconds int64
+		if err := rows.Scan(&startUnix, &netSeconds); err != nil {
+			return nil, err
+		}
+		worked[boundary.Day(time.Unix(startUnix, 0))] += time.Duration(netSeconds) * time.Second
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	totals := make([]DayTotal, 0, len(worked))
+	for day, d := range worked {
+		totals = append(totals, DayTotal{Day: day, Worked: d})
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Day.Before(totals[j].Day) })
+	return totals, nil
+}
+
+type dayTotalRecord struct {
+	Date        string `json:"date" yaml:"date"`
+	WorkSeconds int64  `json:"work_seconds" yaml:"work_seconds"`
+}
+
+func (d DayTotal) record() dayTotalRecord {
+	return dayTotalRecord{Date: d.Day.Format("2006-01-02"), WorkSeconds: seconds(d.Worked)}
+}
+
+func (d DayTotal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.record())
+}
+
+func (d DayTotal) MarshalYAML() (any, error) {
+	return d.record(), nil
+}
@@ -0,0 +1,154 @@
+package timer
+
+// Focused time by hour of the day and by day of the week
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Bucketing is how a Distribution groups the focused time.
+type Bucketing string
+
+const (
+	ByHour    Bucketing = "hour"
+	ByWeekday Bucketing = "weekday"
+)
+
+// DistributionOptions decide the buckets. Hours are those of the
+// boundary's location, and weekdays follow its day start hour and come in
+// order from WeekStart.
+type DistributionOptions struct {
+	By        Bucketing
+	Boundary  DayBoundary
+	WeekStart time.Weekday
+}
+
+// Bucket is the focused time and the completed pomodoros of an hour of the
+// day or a day of the week. A session that runs across buckets is split by
+// its focused time in each, so Pomodoros can be a fraction.
+type Bucket struct {
+	Name      string
+	Worked    time.Duration
+	Pomodoros float64
+}
+
+// Distribution is the focused time of the work sessions in a time frame
+// by hour or by weekday.
+type Distribution struct {
+	By      Bucketing `json:"by" yaml:"by"`
+	Buckets []Bucket  `json:"buckets" yaml:"buckets"`
+}
+
+// Most is the focused time of the busiest bucket.
+func (d *Distribution) Most() time.Duration {
+	var most time.Duration
+	for _, b := range d.Buckets {
+		if b.Worked > most {
+			most = b.Worked
+		}
+	}
+	return most
+}
+
+// ComputeDistribution spreads the focused time of the work sessions that
+// started in the time frame over the hours of the day or the days of the
+// week. Pauses are left out.
+func (s *SQLiteStorage) ComputeDistribution(timeframe TimeFrame, opts DistributionOptions) (*Distribution, error) {
+	loc := opts.Boundary.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	dist := &Distribution{By: opts.By}
+	// bucket finds the bucket of a moment and when the next one starts
+	var bucket func(t time.Time) (int, time.Time)
+	switch opts.By {
+	case ByHour:
+		for hour := 0; hour < 24; hour++ {
+			dist.Buckets = append(dist.Buckets, Bucket{Name: fmt.Sprintf("%02d:00", hour)})
+		}
+		bucket = func(t time.Time) (int, time.Time) {
+			t = t.In(loc)
+			return t.Hour(), time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		}
+	case ByWeekday:
+		for i := 0; i < 7; i++ {
+			dist.Buckets = append(dist.Buckets, Bucket{Name: ((opts.WeekStart + time.Weekday(i)) % 7).String()})
+		}
+		bucket = func(t time.Time) (int, time.Time) {
+			day := opts.Boundary.Day(t)
+			next := time.Date(day.Year(), day.Month(), day.Day()+1, opts.Boundary.Hour, 0, 0, 0, loc)
+			return (int(day.Weekday()) - int(opts.WeekStart) + 7) % 7, next
+		}
+	default:
+		return nil, fmt.Errorf("unknown bucketing %q, use hour or weekday", opts.By)
+	}
+
+	sessions, err := s.QuerySessions(SessionQuery{
+		Since: timeframe.Start,
+		Until: timeframe.End,
+		Kinds: []SessionKind{KindWork},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		net := session.NetDuration()
+		if net <= 0 {
+			continue
+		}
+		for _, segment := range focusedSegments(session) {
+			for t := segment.StartTime; t.Before(segment.EndTime); {
+				i, next := bucket(t)
+				if next.After(segment.EndTime) {
+					next = segment.EndTime
+				}
+				d := next.Sub(t)
+				dist.Buckets[i].Worked += d
+				if session.Status == StatusCompleted {
+					dist.Buckets[i].Pomodoros += float64(d) / float64(net)
+				}
+				t = next
+			}
+		}
+	}
+	return dist, nil
+}
+
+// the parts of a session outside its pauses
+func focusedSegments(session Session) []Pause {
+	pauses := append([]Pause(nil), session.Pauses...)
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i].StartTime.Before(pauses[j].StartTime) })
+
+	var segments []Pause
+	start := session.StartTime
+	for _, p := range pauses {
+		if p.StartTime.After(start) {
+			segments = append(segments, Pause{StartTime: start, EndTime: p.StartTime})
+		}
+		if p.EndTime.After(start) {
+			start = p.EndTime
+		}
+	}
+	if session.EndTime.After(start) {
+		segments = append(segments, Pause{StartTime: start, EndTime: session.EndTime})
+	}
+	return segments
+}
+
+type bucketRecord struct {
+	Name        string  `json:"name" yaml:"name"`
+	WorkSeconds int64   `json:"work_seconds" yaml:"work_seconds"`
+	Pomodoros   float64 `json:"pomodoros" yaml:"pomodoros"`
+}
+
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bucketRecord{Name: b.Name, WorkSeconds: seconds(b.Worked), Pomodoros: b.Pomodoros})
+}
+
+func (b Bucket) MarshalYAML() (any, error) {
+	return bucketRecord{Name: b.Name, WorkSeconds: seconds(b.Worked), Pomodoros: b.Pomodoros}, nil
+}
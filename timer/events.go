@@ -0,0 +1,77 @@
+package timer
+
+// Events published by the PomodoroTimer while it runs
+
+import "time"
+
+type EventType string
+
+const (
+	IntervalStarted   EventType = "interval_started"
+	Tick              EventType = "tick"
+	Paused            EventType = "paused"
+	Resumed           EventType = "resumed"
+	IntervalCompleted EventType = "interval_completed"
+	IntervalSkipped   EventType = "interval_skipped"
+	Aborted           EventType = "aborted"
+)
+
+// Event describes something that happened to the interval that is currently
+// counting down.
+type Event struct {
+	Type EventType
+	Time time.Time
+
+	Kind        SessionKind
+	Label       string
+	Description string
+	// Round is the 1-based number of the work interval the event belongs
+	// to; a break shares the round of the work interval before it.
+	Round    int
+	Position string
+
+	Planned   time.Duration
+	Remaining time.Duration
+
+	// IntervalStart is when the interval began and Pauses are the pauses
+	// taken in it so far; the last one is still open while paused.
+	IntervalStart time.Time
+	Pauses        []Pause
+
+	// Session is the finished interval, set on IntervalCompleted,
+	// IntervalSkipped and Aborted.
+	Session *Session
+}
+
+// Observer receives the events of a PomodoroTimer. Observers are called
+// synchronously, in the order they subscribed, from the goroutine running
+// the timer.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+// ObserverFunc adapts a plain function to the Observer interface.
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// Subscribe registers an observer for every event the timer publishes from
+// now on.
+func (pt *PomodoroTimer) Subscribe(observer Observer) {
+	pt.observers = append(pt.observers, observer)
+}
+
+// publish an event about the current interval to every observer
+func (pt *PomodoroTimer) emit(eventType EventType, remaining time.Duration, session *Session) {
+	event := pt.current
+	event.Type = eventType
+	event.Time = pt.clock.Now()
+	event.Remaining = remaining
+	event.Pauses = append([]Pause(nil), pt.pauses...)
+	event.Session = session
+	for _, observer := range pt.observers {
+		observer.OnEvent(event)
+	}
+}
@@ -0,0 +1,200 @@
+package timer
+
+// Stable JSON and YAML shapes of sessions and statistics for scripts.
+// Durations are whole seconds and times are RFC 3339.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type sessionRecord struct {
+	ID              int           `json:"id" yaml:"id"`
+	Label           string        `json:"label" yaml:"label"`
+	Kind            SessionKind   `json:"kind" yaml:"kind"`
+	Status          SessionStatus `json:"status" yaml:"status"`
+	StartTime       time.Time     `json:"start_time" yaml:"start_time"`
+	EndTime         time.Time     `json:"end_time" yaml:"end_time"`
+	DurationSeconds int64         `json:"duration_seconds" yaml:"duration_seconds"`
+	NetSeconds      int64         `json:"net_seconds" yaml:"net_seconds"`
+	PausedSeconds   int64         `json:"paused_seconds" yaml:"paused_seconds"`
+	PlannedSeconds  int64         `json:"planned_seconds" yaml:"planned_seconds"`
+	Pauses          []Pause       `json:"pauses" yaml:"pauses"`
+	Tags            []string      `json:"tags" yaml:"tags"`
+	Note            string        `json:"note" yaml:"note"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty"`
+}
+
+func (s Session) record() sessionRecord {
+	r := sessionRecord{
+		ID:              s.ID,
+		Label:           s.Label,
+		Kind:            s.Kind,
+		Status:          s.Status,
+		StartTime:       s.StartTime,
+		EndTime:         s.EndTime,
+		DurationSeconds: seconds(s.Duration()),
+		NetSeconds:      seconds(s.NetDuration()),
+		PausedSeconds:   seconds(s.PausedDuration()),
+		PlannedSeconds:  seconds(s.PlannedDuration),
+		Pauses:          s.Pauses,
+		Tags:            s.Tags,
+		Note:            s.Note,
+	}
+	// empty lists rather than null, so consumers need not check
+	if r.Pauses == nil {
+		r.Pauses = []Pause{}
+	}
+	if r.Tags == nil {
+		r.Tags = []string{}
+	}
+	if s.Deleted() {
+		deletedAt := s.DeletedAt
+		r.DeletedAt = &deletedAt
+	}
+	return r
+}
+
+func (s Session) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.record())
+}
+
+func (s Session) MarshalYAML() (any, error) {
+	return s.record(), nil
+}
+
+type timeFrameRecord struct {
+	Name  string     `json:"name" yaml:"name"`
+	Start *time.Time `json:"start" yaml:"start"`
+	End   *time.Time `json:"end" yaml:"end"`
+}
+
+func (tf TimeFrame) record() timeFrameRecord {
+	r := timeFrameRecord{Name: tf.Name}
+	if !tf.Start.IsZero() {
+		r.Start = &tf.Start
+	}
+	if !tf.End.IsZero() {
+		r.End = &tf.End
+	}
+	return r
+}
+
+// MarshalJSON writes an open side of the frame as null.
+func (tf TimeFrame) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tf.record())
+}
+
+func (tf TimeFrame) MarshalYAML() (any, error) {
+	return tf.record(), nil
+}
+
+// LabelStats are the statistics of a single label in a PomoStats.
+type LabelStats struct {
+	Label           string        `json:"label" yaml:"label"`
+	Sessions        int           `json:"sessions" yaml:"sessions"`
+	WorkDuration    time.Duration `json:"-" yaml:"-"`
+	WorkSeconds     int64         `json:"work_seconds" yaml:"work_seconds"`
+	PlannedAchieved *float64      `json:"planned_achieved" yaml:"planned_achieved"`
+}
+
+// Labels merges the per label maps of the statistics into one list, sorted
+// by work time, most first.
+func (p *PomoStats) Labels() []LabelStats {
+	byLabel := map[string]*LabelStats{}
+	get := func(label string) *LabelStats {
+		if ls, ok := byLabel[label]; ok {
+			return ls
+		}
+		ls := &LabelStats{Label: label}
+		byLabel[label] = ls
+		return ls
+	}
+	for label, n := range p.PomosPerLabel {
+		get(label).Sessions = n
+	}
+	for label, d := range p.TimeSpentPerLabel {
+		ls := get(label)
+		ls.WorkDuration = d
+		ls.WorkSeconds = seconds(d)
+	}
+	for label, achieved := range p.PlannedAchievedPerLabel {
+		achieved := achieved
+		get(label).PlannedAchieved = &achieved
+	}
+
+	labels := make([]LabelStats, 0, len(byLabel))
+	for _, ls := range byLabel {
+		labels = append(labels, *ls)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].WorkDuration != labels[j].WorkDuration {
+			return labels[i].WorkDuration > labels[j].WorkDuration
+		}
+		return labels[i].Label < labels[j].Label
+	})
+	return labels
+}
+
+type statsRecord struct {
+	TimeFrame                TimeFrame        `json:"timeframe" yaml:"timeframe"`
+	TotalSessions            int              `json:"total_sessions" yaml:"total_sessions"`
+	TotalWorkSeconds         int64            `json:"total_work_seconds" yaml:"total_work_seconds"`
+	TotalBreakSeconds        int64            `json:"total_break_seconds" yaml:"total_break_seconds"`
+	AverageSessionSeconds    int64            `json:"average_session_seconds" yaml:"average_session_seconds"`
+	LongestSessionSeconds    int64            `json:"longest_session_seconds" yaml:"longest_session_seconds"`
+	ShortestSessionSeconds   int64            `json:"shortest_session_seconds" yaml:"shortest_session_seconds"`
+	LongestSessionLabel      string           `json:"longest_session_label" yaml:"longest_session_label"`
+	MedianSessionSeconds     int64            `json:"median_session_seconds" yaml:"median_session_seconds"`
+	SessionPercentileSeconds map[string]int64 `json:"session_percentile_seconds" yaml:"session_percentile_seconds"`
+	CompletionRate           float64          `json:"completion_rate" yaml:"completion_rate"`
+	Labels                   []LabelStats     `json:"labels" yaml:"labels"`
+	Goals                    []GoalProgress   `json:"goals" yaml:"goals"`
+	Streaks                  []Streak         `json:"streaks" yaml:"streaks"`
+	Distribution             *Distribution    `json:"distribution,omitempty" yaml:"distribution,omitempty"`
+}
+
+func (p PomoStats) record() statsRecord {
+	r := statsRecord{
+		TimeFrame:              p.TimeFrame,
+		TotalSessions:          p.TotalSessions,
+		TotalWorkSeconds:       seconds(p.TotalWorkDuration),
+		TotalBreakSeconds:      seconds(p.TotalBreakDuration),
+		AverageSessionSeconds:  seconds(p.AverageSessionDuration),
+		LongestSessionSeconds:  seconds(p.LongestSession),
+		ShortestSessionSeconds: seconds(p.ShortestSession),
+		LongestSessionLabel:    p.LongestSessionLabel,
+		MedianSessionSeconds:   seconds(p.MedianSession),
+		CompletionRate:         p.CompletionRate,
+		Labels:                 p.Labels(),
+		Goals:                  p.Goals,
+		Streaks:                p.Streaks,
+		Distribution:           p.Distribution,
+	}
+	if r.Goals == nil {
+		r.Goals = []GoalProgress{}
+	}
+	if r.Streaks == nil {
+		r.Streaks = []Streak{}
+	}
+	r.SessionPercentileSeconds = make(map[string]int64, len(p.SessionPercentiles))
+	for pct, d := range p.SessionPercentiles {
+		r.SessionPercentileSeconds[fmt.Sprintf("p%d", pct)] = seconds(d)
+	}
+	return r
+}
+
+func (p PomoStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.record())
+}
+
+func (p PomoStats) MarshalYAML() (any, error) {
+	return p.record(), nil
+}
+
+// whole seconds of a duration
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
@@ -0,0 +1,265 @@
+package timer
+
+// Daily and weekly focus goals
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GoalPeriod is how often a goal starts over.
+type GoalPeriod string
+
+const (
+	GoalDaily  GoalPeriod = "daily"
+	GoalWeekly GoalPeriod = "weekly"
+)
+
+// ErrGoalNotFound is returned for an ID that matches no goal.
+var ErrGoalNotFound = errors.New("goal not found")
+
+// Goal is a target of completed pomodoros or of focused time per day or
+// week. Exactly one of Pomodoros and Duration is set. An empty Label counts
+// the work sessions of every label.
+type Goal struct {
+	ID        int
+	Period    GoalPeriod
+	Label     string
+	Pomodoros int
+	Duration  time.Duration
+}
+
+// Applies reports whether a session counts toward the goal.
+func (g Goal) Applies(session Session) bool {
+	return session.Kind == KindWork && (g.Label == "" || g.Label == session.Label)
+}
+
+// TimeFrame is the day, or the week from weekStart, that now falls in, with
+// days decided by days as for streaks and the heatmap.
+func (g Goal) TimeFrame(now time.Time, days DayBoundary, weekStart time.Weekday) TimeFrame {
+	if g.Period == GoalWeekly {
+		first := days.Week(now, weekStart)
+		return TimeFrame{Start: days.Start(first), End: days.Start(first.AddDate(0, 0, 7)), Name: "week"}
+	}
+	day := days.Day(now)
+	return TimeFrame{Start: days.Start(day), End: days.Start(day.AddDate(0, 0, 1)), Name: "today"}
+}
+
+// Name names the goal, e.g. "Daily Coding", or "Weekly" for a goal over
+// every label.
+func (g Goal) Name() string {
+	name := "Daily"
+	if g.Period == GoalWeekly {
+		name = "Weekly"
+	}
+	if g.Label != "" {
+		name += " " + g.Label
+	}
+	return name
+}
+
+// Target describes the goal, e.g. "6 pomodoros" or "20h0m0s".
+func (g Goal) Target() string {
+	if g.Pomodoros > 0 {
+		return fmt.Sprintf("%d pomodoros", g.Pomodoros)
+	}
+	return g.Duration.String()
+}
+
+func (g Goal) validate() error {
+	if g.Period != GoalDaily && g.Period != GoalWeekly {
+		return fmt.Errorf("unknown goal period %q, use daily or weekly", g.Period)
+	}
+	if (g.Pomodoros > 0) == (g.Duration > 0) {
+		return fmt.Errorf("a goal needs either a number of pomodoros or a duration")
+	}
+	if g.Pomodoros < 0 || g.Duration < 0 {
+		return fmt.Errorf("goal targets must not be negative")
+	}
+	return nil
+}
+
+// GoalProgress is how far along a goal is in its current period.
+// Pomodoros counts the completed work intervals and Worked the focused
+// time of every work session, completed or not.
+type GoalProgress struct {
+	Goal      Goal
+	TimeFrame TimeFrame
+	Pomodoros int
+	Worked    time.Duration
+}
+
+// Fraction is the share of the target reached so far, capped at 1.
+func (p GoalProgress) Fraction() float64 {
+	var f float64
+	if p.Goal.Pomodoros > 0 {
+		f = float64(p.Pomodoros) / float64(p.Goal.Pomodoros)
+	} else if p.Goal.Duration > 0 {
+		f = float64(p.Worked) / float64(p.Goal.Duration)
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// Done reports whether the target is reached.
+func (p GoalProgress) Done() bool {
+	if p.Goal.Pomodoros > 0 {
+		return p.Pomodoros >= p.Goal.Pomodoros
+	}
+	return p.Worked >= p.Goal.Duration
+}
+
+// Progress describes how far along the goal is, e.g. "4/6 pomodoros" or
+// "12h30m0s/20h0m0s".
+func (p GoalProgress) Progress() string {
+	if p.Goal.Pomodoros > 0 {
+		return fmt.Sprintf("%d/%d pomodoros", p.Pomodoros, p.Goal.Pomodoros)
+	}
+	return fmt.Sprintf("%s/%s", p.Worked.Round(time.Minute), p.Goal.Duration)
+}
+
+// reachedBy reports whether the goal was reached by the session, that is
+// whether it is done now but was not without the session
+func (p GoalProgress) reachedBy(session Session) bool {
+	if !p.Done() || !p.Goal.Applies(session) {
+		return false
+	}
+	before := p
+	before.Worked -= session.NetDuration()
+	if session.Status == StatusCompleted {
+		before.Pomodoros--
+	}
+	return !before.Done()
+}
+
+type goalProgressRecord struct {
+	ID              int        `json:"id" yaml:"id"`
+	Period          GoalPeriod `json:"period" yaml:"period"`
+	Label           string     `json:"label" yaml:"label"`
+	TargetPomodoros int        `json:"target_pomodoros" yaml:"target_pomodoros"`
+	TargetSeconds   int64      `json:"target_seconds" yaml:"target_seconds"`
+	Pomodoros       int        `json:"pomodoros" yaml:"pomodoros"`
+	WorkedSeconds   int64      `json:"worked_seconds" yaml:"worked_seconds"`
+	Fraction        float64    `json:"fraction" yaml:"fraction"`
+	Done            bool       `json:"done" yaml:"done"`
+	TimeFrame       TimeFrame  `json:"timeframe" yaml:"timeframe"`
+}
+
+func (p GoalProgress) record() goalProgressRecord {
+	return goalProgressRecord{
+		ID:              p.Goal.ID,
+		Period:          p.Goal.Period,
+		Label:           p.Goal.Label,
+		TargetPomodoros: p.Goal.Pomodoros,
+		TargetSeconds:   seconds(p.Goal.Duration),
+		Pomodoros:       p.Pomodoros,
+		WorkedSeconds:   seconds(p.Worked),
+		Fraction:        p.Fraction(),
+		Done:            p.Done(),
+		TimeFrame:       p.TimeFrame,
+	}
+}
+
+func (p GoalProgress) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.record())
+}
+
+func (p GoalProgress) MarshalYAML() (any, error) {
+	return p.record(), nil
+}
+
+// SetGoal saves a goal, replacing the goal with the same period and label
+// if there is one, and sets its ID.
+func (s *SQLiteStorage) SetGoal(goal *Goal) error {
+	if err := goal.validate(); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO goals (period, label, target_pomodoros, target_seconds)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (period, label) DO UPDATE SET
+			target_pomodoros = excluded.target_pomodoros,
+			target_seconds = excluded.target_seconds
+	`, string(goal.Period), goal.Label, goal.Pomodoros, seconds(goal.Duration))
+	if err != nil {
+		return err
+	}
+	return s.db.QueryRow(`SELECT id FROM goals WHERE period = ? AND label = ?`, string(goal.Period), goal.Label).Scan(&goal.ID)
+}
+
+// ListGoals lists the goals, daily ones first.
+func (s *SQLiteStorage) ListGoals() ([]Goal, error) {
+	rows, err := s.db.Query(`
+		SELECT id, period, label, target_pomodoros, target_seconds
+		FROM goals
+		ORDER BY period ASC, label ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []Goal
+	for rows.Next() {
+		var goal Goal
+		var period string
+		var targetSeconds int64
+		if err := rows.Scan(&goal.ID, &period, &goal.Label, &goal.Pomodoros, &targetSeconds); err != nil {
+			return nil, err
+		}
+		goal.Period = GoalPeriod(period)
+		goal.Duration = time.Duration(targetSeconds) * time.Second
+		goals = append(goals, goal)
+	}
+	return goals, rows.Err()
+}
+
+// RemoveGoal deletes a goal.
+func (s *SQLiteStorage) RemoveGoal(id int) error {
+	result, err := s.db.Exec(`DELETE FROM goals WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %d", ErrGoalNotFound, id)
+	}
+	return nil
+}
+
+// GoalProgress reports the progress of every goal in the period that now
+// falls in, see Goal.TimeFrame.
+func (s *SQLiteStorage) GoalProgress(now time.Time, days DayBoundary, weekStart time.Weekday) ([]GoalProgress, error) {
+	goals, err := s.ListGoals()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		p := GoalProgress{Goal: goal, TimeFrame: goal.TimeFrame(now, days, weekStart)}
+		start, end := p.TimeFrame.bounds()
+		var workedSeconds sql.NullInt64
+		err := s.db.QueryRow(`
+			SELECT
+				COALESCE(SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END), 0),
+				SUM(`+netDurationSQL+`)
+			FROM sessions
+			WHERE kind = 'work' AND deleted_at IS NULL
+				AND (? = '' OR label = ?)
+				AND start_time >= ? AND start_time < ?
+		`, goal.Label, goal.Label, start, end).Scan(&p.Pomodoros, &workedSeconds)
+		if err != nil {
+			return nil, err
+		}
+		p.Worked = time.Duration(workedSeconds.Int64) * time.Second
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
@@ -0,0 +1,289 @@
+package timer
+
+// Versioned schema migrations for the session database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Migration upgrades the schema by one version. Migrations run in order,
+// each in its own transaction, and are recorded in the schema_migrations
+// table once applied.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *sql.Tx) error
+}
+
+// MigrationStatus reports whether a migration has been applied to a
+// database and when; AppliedAt is zero for pending migrations.
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+func (m MigrationStatus) Applied() bool {
+	return !m.AppliedAt.IsZero()
+}
+
+// migrations are never edited or reordered once released: add a new one
+// instead. The ones after the baseline tolerate databases upgraded by
+// versions that altered the schema on the fly before migrations existed.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create the sessions table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS sessions (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					label TEXT NOT NULL,
+					start_time INTEGER NOT NULL,
+					end_time INTEGER NOT NULL
+				)
+			`)
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "add the kind of each session",
+		Up: func(tx *sql.Tx) error {
+			// rows saved before intervals were tracked separately are work sessions
+			return addColumnIfMissing(tx, "sessions", "kind", "TEXT NOT NULL DEFAULT 'work'")
+		},
+	},
+	{
+		Version:     3,
+		Description: "add the status and planned duration of each session",
+		Up: func(tx *sql.Tx) error {
+			// older rows have no outcome recorded; a planned duration of 0 means unknown
+			if err := addColumnIfMissing(tx, "sessions", "status", "TEXT NOT NULL DEFAULT 'completed'"); err != nil {
+				return err
+			}
+			return addColumnIfMissing(tx, "sessions", "planned_seconds", "INTEGER NOT NULL DEFAULT 0")
+		},
+	},
+	{
+		Version:     4,
+		Description: "create the pauses table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS pauses (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+					start_time INTEGER NOT NULL,
+					end_time INTEGER NOT NULL
+				)
+			`)
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "add notes and soft deletes to sessions",
+		Up: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing(tx, "sessions", "note", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+			// NULL unless the session was deleted
+			return addColumnIfMissing(tx, "sessions", "deleted_at", "INTEGER")
+		},
+	},
+	{
+		Version:     6,
+		Description: "create the session tags table",
+		Up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS session_tags (
+					session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+					tag TEXT NOT NULL,
+					PRIMARY KEY (session_id, tag)
+				)
+			`)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS session_tags_tag ON session_tags (tag)`)
+			return err
+		},
+	},
+	{
+		Version:     7,
+		Description: "create the goals table",
+		Up: func(tx *sql.Tx) error {
+			// an empty label is a goal over every label
+			_, err := tx.Exec(`
+				CREATE TABLE IF NOT EXISTS goals (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					period TEXT NOT NULL,
+					label TEXT NOT NULL DEFAULT '',
+					target_pomodoros INTEGER NOT NULL DEFAULT 0,
+					target_seconds INTEGER NOT NULL DEFAULT 0,
+					UNIQUE (period, label)
+				)
+			`)
+			return err
+		},
+	},
+}
+
+// Migrations lists every migration, oldest first.
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// LatestSchemaVersion is the version of the schema once every migration is
+// applied.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate applies the pending migrations up to and including version target
+// and returns the ones it applied. A target of 0 applies all of them.
+func Migrate(db *sql.DB, target int) ([]Migration, error) {
+	if target == 0 {
+		target = LatestSchemaVersion()
+	}
+	if err := createMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+	for version := range applied {
+		if version > LatestSchemaVersion() {
+			return nil, fmt.Errorf("the database has schema version %d, newer than this version of pomo supports (%d)", version, LatestSchemaVersion())
+		}
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return done, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// ReadMigrationStatus reports which migrations have been applied to the
+// database at path, without applying any.
+func ReadMigrationStatus(path string) ([]MigrationStatus, error) {
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return migrationStatus(db)
+}
+
+// migrationStatus pairs every migration with the time it was applied
+func migrationStatus(db *sql.DB) ([]MigrationStatus, error) {
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	applied := map[int]time.Time{}
+	if exists {
+		if applied, err = appliedMigrations(db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, MigrationStatus{Migration: m, AppliedAt: applied[m.Version]})
+	}
+	return statuses, nil
+}
+
+func createMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)
+	`)
+	return err
+}
+
+// the versions recorded in schema_migrations and when they were applied
+func appliedMigrations(db *sql.DB) (map[int]time.Time, error) {
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = time.Unix(appliedAt, 0)
+	}
+	return applied, rows.Err()
+}
+
+// run a migration and record it in the same transaction
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.Up(tx); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO schema_migrations (version, description, applied_at)
+		VALUES (?, ?, ?)
+	`, m.Version, m.Description, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// add a column to an existing table unless it is already there
+func addColumnIfMissing(tx *sql.Tx, table string, column string, definition string) error {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
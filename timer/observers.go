@@ -0,0 +1,162 @@
+package timer
+
+// Subscribers that turn timer events into terminal output, desktop
+// notifications and saved sessions
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/gen2brain/beeep"
+	"github.com/schollz/progressbar/v3"
+)
+
+// TerminalObserver draws a progress bar for every interval.
+type TerminalObserver struct {
+	bar *progressbar.ProgressBar
+}
+
+func NewTerminalObserver() *TerminalObserver {
+	return &TerminalObserver{}
+}
+
+func (t *TerminalObserver) OnEvent(event Event) {
+	switch event.Type {
+	case IntervalStarted:
+		switch event.Kind {
+		case KindWork:
+			fmt.Printf("Starting Pomodoro Timer for %s for %s\n", event.Label, event.Planned.String())
+		case KindLongBreak:
+			fmt.Println("\nCycle completed, great job! Take a long break.")
+		default:
+			fmt.Println("\nWork completed, good job! Take a break.")
+		}
+		t.bar = progressbar.NewOptions64(
+			int64(event.Planned.Seconds()),
+			progressbar.OptionSetWidth(30),
+			progressbar.OptionShowCount(),
+			progressbar.OptionClearOnFinish(),
+			progressbar.OptionSetDescription(describeRemaining(event)),
+			progressbar.OptionSetTheme(progressbar.Theme{
+				Saucer:        "█",
+				SaucerPadding: "░",
+				BarStart:      "[",
+				BarEnd:        "]",
+			}),
+		)
+		// a resumed interval starts part of the way through
+		t.bar.Set64(int64((event.Planned - event.Remaining).Seconds()))
+	case Tick:
+		t.bar.Add(1)
+		// Update label with time left (mm:ss), cyan text
+		t.bar.Describe(describeRemaining(event))
+	case Paused:
+		t.bar.Describe(color.YellowString("⏸ Paused - press 'r' to resume"))
+	case Resumed:
+		t.bar.Describe(color.GreenString("▶ Resumed: %s", event.Description))
+	case IntervalCompleted:
+		fmt.Println(color.GreenString("\n✅ %s completed!", event.Description))
+		if event.Kind.IsBreak() {
+			fmt.Println("\nBreak completed. Back to work.")
+		}
+	case IntervalSkipped:
+		fmt.Println(color.YellowString("\n⏭ %s skipped.", event.Description))
+	case Aborted:
+		fmt.Println(color.RedString("\n⏹ Timer stopped early."))
+	}
+}
+
+func describeRemaining(event Event) string {
+	return color.CyanString("▶ %s [%02d:%02d]", event.Description,
+		int(event.Remaining.Minutes()), int(event.Remaining.Seconds())%60)
+}
+
+// NotificationObserver sends a desktop notification and rings the terminal
+// bell whenever an interval completes.
+type NotificationObserver struct {
+	Desktop bool
+	Bell    bool
+}
+
+func (n NotificationObserver) OnEvent(event Event) {
+	if event.Type != IntervalCompleted {
+		return
+	}
+
+	// Desktop notification
+	var err error
+	if n.Desktop {
+		err = beeep.Notify(event.Label, fmt.Sprintf("%s completed!", event.Description), "")
+	}
+
+	// Terminal beep (may or may not work depending on system)
+	if n.Bell {
+		fmt.Print("\a")
+		beeep.Beep(500, 200)
+	}
+
+	if err != nil {
+		fmt.Println(color.RedString("Error sending notification: %v", err))
+	}
+}
+
+// StorageObserver saves every finished interval, completed or not, as its
+// own session.
+type StorageObserver struct {
+	Storage Storage
+}
+
+func (s StorageObserver) OnEvent(event Event) {
+	if event.Session == nil {
+		return
+	}
+	if err := s.Storage.SaveTimerData(event.Session); err != nil {
+		fmt.Println(color.RedString("Error saving session: %v", err))
+	}
+}
+
+// GoalStore reports the progress of the goals, see SQLiteStorage.
+type GoalStore interface {
+	GoalProgress(now time.Time, days DayBoundary, weekStart time.Weekday) ([]GoalProgress, error)
+}
+
+// GoalObserver congratulates whenever a completed work interval reaches a
+// goal. It reads the progress back from the database, so it has to run
+// after the StorageObserver. Days and WeekStart decide the periods of the
+// goals, as for GoalStore.GoalProgress.
+type GoalObserver struct {
+	Goals     GoalStore
+	Days      DayBoundary
+	WeekStart time.Weekday
+	Desktop   bool
+	Bell      bool
+}
+
+func (g GoalObserver) OnEvent(event Event) {
+	if event.Type != IntervalCompleted || event.Session == nil || event.Kind != KindWork {
+		return
+	}
+
+	// the session counts toward the day it started on
+	progress, err := g.Goals.GoalProgress(event.Session.StartTime, g.Days, g.WeekStart)
+	if err != nil {
+		fmt.Println(color.RedString("Error checking goals: %v", err))
+		return
+	}
+	for _, p := range progress {
+		if !p.reachedBy(*event.Session) {
+			continue
+		}
+		message := fmt.Sprintf("%s goal reached: %s", p.Goal.Name(), p.Progress())
+		fmt.Println(color.GreenString("\n🎯 %s", message))
+		if g.Desktop {
+			if err := beeep.Notify("Goal reached", message, ""); err != nil {
+				fmt.Println(color.RedString("Error sending notification: %v", err))
+			}
+		}
+		if g.Bell {
+			fmt.Print("\a")
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package timer
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processStart identifies the run of the process with the given pid, by the
+// boot it runs in and its start time since that boot, so that another
+// process that reused the pid tells apart from it
+func processStart(pid int) (string, bool) {
+	bootID, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return "", false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return "", false
+	}
+	// the command name in parentheses may contain spaces, the fields after
+	// it start with the state, the 3rd field, and the start time is the 22nd
+	i := strings.LastIndexByte(string(stat), ')')
+	if i < 0 {
+		return "", false
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return "", false
+	}
+	return strings.TrimSpace(string(bootID)) + "/" + fields[19], true
+}
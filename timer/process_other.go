@@ -0,0 +1,9 @@
+//go:build !linux
+
+package timer
+
+// processStart is only known on Linux, elsewhere a process is told apart by
+// its pid alone
+func processStart(pid int) (string, bool) {
+	return "", false
+}
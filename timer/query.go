@@ -0,0 +1,213 @@
+package timer
+
+// Filtering, sorting and paging of the session history
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is go-sqlite3 with a REGEXP function, which sqlite leaves to
+// the application
+const driverName = "sqlite3_pomolite"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", matchRegexp, true)
+		},
+	})
+}
+
+// compiled patterns of the REGEXP function, by source
+var regexpCache sync.Map
+
+// implement `value REGEXP pattern`
+func matchRegexp(pattern string, value string) (bool, error) {
+	if re, ok := regexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(value), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	regexpCache.Store(pattern, re)
+	return re.MatchString(value), nil
+}
+
+// LabelMatch tells how SessionQuery.Label is compared to labels.
+type LabelMatch string
+
+const (
+	LabelExact LabelMatch = "exact"
+	// LabelGlob matches shell patterns such as "cod*" or "[Cc]oding".
+	LabelGlob LabelMatch = "glob"
+	// LabelRegex matches Go regular expressions anywhere in the label.
+	LabelRegex LabelMatch = "regex"
+)
+
+// SessionOrder is the column SessionQuery sorts by.
+type SessionOrder string
+
+const (
+	OrderStart    SessionOrder = "start"
+	OrderEnd      SessionOrder = "end"
+	OrderDuration SessionOrder = "duration"
+	OrderLabel    SessionOrder = "label"
+	OrderID       SessionOrder = "id"
+)
+
+// orderColumns maps every SessionOrder to the SQL it sorts by
+var orderColumns = map[SessionOrder]string{
+	OrderStart:    "start_time",
+	OrderEnd:      "end_time",
+	OrderDuration: netDurationSQL,
+	OrderLabel:    "label",
+	OrderID:       "id",
+}
+
+// SessionQuery selects, sorts and pages sessions. The zero value lists
+// every session that is not deleted, the most recent first.
+type SessionQuery struct {
+	Label      string
+	LabelMatch LabelMatch
+
+	// Since and Until bound the start time of the sessions: Since is
+	// inclusive and Until exclusive. Zero values leave them open.
+	Since time.Time
+	Until time.Time
+
+	// MinDuration and MaxDuration bound the net duration, pauses excluded.
+	// Zero values leave them open.
+	MinDuration time.Duration
+	MaxDuration time.Duration
+
+	// Statuses and Kinds keep the sessions with any of the given values.
+	Statuses []SessionStatus
+	Kinds    []SessionKind
+	// Tags keeps the sessions that have every one of the given tags.
+	Tags []string
+
+	// Deleted lists the deleted sessions instead of the others.
+	Deleted bool
+
+	// OrderBy defaults to OrderStart, newest first unless Ascending.
+	OrderBy   SessionOrder
+	Ascending bool
+
+	// Limit of 0 returns every matching session, after skipping Offset.
+	Limit  int
+	Offset int
+}
+
+// QuerySessions lists the sessions matching the query.
+func (s *SQLiteStorage) QuerySessions(q SessionQuery) ([]Session, error) {
+	where, args, err := q.where()
+	if err != nil {
+		return nil, err
+	}
+
+	orderBy := q.OrderBy
+	if orderBy == "" {
+		orderBy = OrderStart
+	}
+	column, ok := orderColumns[orderBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort order %q", orderBy)
+	}
+	direction := "DESC"
+	if q.Ascending {
+		direction = "ASC"
+	}
+
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY ` + column + ` ` + direction + `, id ` + direction
+	if q.Limit < 0 || q.Offset < 0 {
+		return nil, fmt.Errorf("limit and offset must not be negative")
+	}
+	if q.Limit > 0 || q.Offset > 0 {
+		// sqlite only takes an OFFSET after a LIMIT, and -1 is no limit
+		limit := q.Limit
+		if limit == 0 {
+			limit = -1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, q.Offset)
+	}
+	return s.selectSessions(query, args...)
+}
+
+// build the conditions of the query, to be joined with AND
+func (q SessionQuery) where() ([]string, []any, error) {
+	where := []string{"deleted_at IS NULL"}
+	if q.Deleted {
+		where[0] = "deleted_at IS NOT NULL"
+	}
+	var args []any
+
+	if q.Label != "" {
+		switch q.LabelMatch {
+		case LabelExact, "":
+			where = append(where, "label = ?")
+		case LabelGlob:
+			where = append(where, "label GLOB ?")
+		case LabelRegex:
+			if _, err := regexp.Compile(q.Label); err != nil {
+				return nil, nil, fmt.Errorf("invalid label pattern: %w", err)
+			}
+			where = append(where, "label REGEXP ?")
+		default:
+			return nil, nil, fmt.Errorf("unknown label match %q", q.LabelMatch)
+		}
+		args = append(args, q.Label)
+	}
+
+	if !q.Since.IsZero() {
+		where = append(where, "start_time >= ?")
+		args = append(args, q.Since.Unix())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "start_time < ?")
+		args = append(args, q.Until.Unix())
+	}
+	if q.MinDuration > 0 {
+		where = append(where, netDurationSQL+" >= ?")
+		args = append(args, int64(q.MinDuration.Seconds()))
+	}
+	if q.MaxDuration > 0 {
+		where = append(where, netDurationSQL+" <= ?")
+		args = append(args, int64(q.MaxDuration.Seconds()))
+	}
+
+	if len(q.Statuses) > 0 {
+		where = append(where, "status IN ("+placeholders(len(q.Statuses))+")")
+		for _, status := range q.Statuses {
+			args = append(args, string(status))
+		}
+	}
+	if len(q.Kinds) > 0 {
+		where = append(where, "kind IN ("+placeholders(len(q.Kinds))+")")
+		for _, kind := range q.Kinds {
+			args = append(args, string(kind))
+		}
+	}
+	for _, tag := range q.Tags {
+		where = append(where, "EXISTS (SELECT 1 FROM session_tags t WHERE t.session_id = sessions.id AND t.tag = ?)")
+		args = append(args, tag)
+	}
+	return where, args, nil
+}
+
+// n comma separated placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
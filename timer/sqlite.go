@@ -2,30 +2,76 @@ package timer
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-type TimeFrame struct {
-	start time.Time
-	end   time.Time
-}
+// netDurationSQL is the focused time of a row in the sessions table, in
+// seconds: its gross duration minus the time spent in its pauses.
+const netDurationSQL = `(end_time - start_time - COALESCE(
+	(SELECT SUM(p.end_time - p.start_time) FROM pauses p WHERE p.session_id = sessions.id), 0))`
+
+// sessionColumns are the columns scanSessions reads, in order
+const sessionColumns = `id, label, kind, status, planned_seconds, start_time, end_time, note, deleted_at`
 
 type SQLiteStorage struct {
 	db *sql.DB
 }
 
 func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
-	db, err := sql.Open("sqlite3", path)
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite has a single writer, and every connection to ":memory:" would
+	// otherwise get its own empty database
+	db.SetMaxOpenConns(1)
+
+	if _, err := Migrate(db, 0); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStorage{db: db}, nil
+}
+
+// ErrSchemaOutdated is returned by NewReadOnlySQLiteStorage for a database
+// with pending migrations.
+var ErrSchemaOutdated = errors.New("the database schema is out of date")
+
+// characters with a meaning in an sqlite URI
+var uriEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// NewReadOnlySQLiteStorage opens the database at path for reading only,
+// leaving the file as it is. Its schema is not migrated, so a database with
+// pending migrations is an ErrSchemaOutdated.
+func NewReadOnlySQLiteStorage(path string) (*SQLiteStorage, error) {
+	db, err := sql.Open(driverName, "file:"+uriEscaper.Replace(path)+"?mode=ro")
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(1)
 
-	if err := initTable(db); err != nil {
+	statuses, err := migrationStatus(db)
+	if err != nil {
+		db.Close()
 		return nil, err
 	}
+	pending := 0
+	for _, m := range statuses {
+		if !m.Applied() {
+			pending++
+		}
+	}
+	if pending > 0 {
+		db.Close()
+		return nil, fmt.Errorf("%w: %s has %d pending migration(s)", ErrSchemaOutdated, path, pending)
+	}
 	return &SQLiteStorage{db: db}, nil
 }
 
@@ -34,285 +80,625 @@ func (s *SQLiteStorage) Close() error {
 }
 
 // save the timer data to the database
-func (s *SQLiteStorage) SaveTimerData(label string, startTime time.Time, endTime time.Time) error {
-	_, err := s.db.Exec(`
-		INSERT INTO sessions (label, start_time, end_time)
-		VALUES (?, ?, ?)
-	`, label, startTime.Unix(), endTime.Unix())
+func (s *SQLiteStorage) SaveTimerData(session *Session) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	fmt.Printf("Timer saved successfully with label: %s, start time: %s, end time: %s\n", label, startTime, endTime)
+	id, err := insertSession(tx, session)
 	if err != nil {
 		return err
 	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	session.ID = int(id)
 	return nil
 }
 
-func (s *SQLiteStorage) ListSessions(count int) ([]Session, error) {
-	// if count is 0, return all sessions
+// insert a session and its pauses, filling in the default kind and status
+func insertSession(tx *sql.Tx, session *Session) (int64, error) {
+	if session.Kind == "" {
+		session.Kind = KindWork
+	}
+	if session.Status == "" {
+		session.Status = StatusCompleted
+	}
 
-	query := `
-		SELECT id, label, start_time, end_time
-		FROM sessions
-		ORDER BY start_time DESC
-	`
-	var rows *sql.Rows
-	var err error
-	if count > 0 {
-		query += " LIMIT ?"
-		rows, err = s.db.Query(query, count)
+	var deletedAt sql.NullInt64
+	if session.Deleted() {
+		deletedAt = sql.NullInt64{Int64: session.DeletedAt.Unix(), Valid: true}
+	}
+	result, err := tx.Exec(`
+		INSERT INTO sessions (label, kind, status, planned_seconds, start_time, end_time, note, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.Label, string(session.Kind), string(session.Status), int64(session.PlannedDuration.Seconds()),
+		session.StartTime.Unix(), session.EndTime.Unix(), session.Note, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, pause := range session.Pauses {
+		_, err := tx.Exec(`
+			INSERT INTO pauses (session_id, start_time, end_time)
+			VALUES (?, ?, ?)
+		`, id, pause.StartTime.Unix(), pause.EndTime.Unix())
 		if err != nil {
-			return nil, err
+			return 0, err
+		}
+	}
+	if err := insertTags(tx, id, session.Tags); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// attach tags to a session, ignoring empty and repeated ones
+func insertTags(tx *sql.Tx, sessionID int64, tags []string) error {
+	for _, tag := range tags {
+		if tag == "" {
+			continue
 		}
-		defer rows.Close()
-	} else {
-		rows, err = s.db.Query(query)
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO session_tags (session_id, tag)
+			VALUES (?, ?)
+		`, sessionID, tag)
 		if err != nil {
-			return nil, err
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeFrom copies the sessions of another database into this one, in a
+// single transaction, deleted sessions included so they can still be
+// restored. Sessions already present, with the same label, kind, start and
+// end, are skipped so merging twice is harmless.
+func (s *SQLiteStorage) MergeFrom(src *SQLiteStorage) (merged int, skipped int, err error) {
+	sessions, err := src.QuerySessions(SessionQuery{OrderBy: OrderID, Ascending: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	deleted, err := src.QuerySessions(SessionQuery{Deleted: true, OrderBy: OrderID, Ascending: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	sessions = append(sessions, deleted...)
+	// oldest first, so the merged sessions get ids in chronological order
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	for _, session := range sessions {
+		var exists bool
+		err := tx.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM sessions
+				WHERE label = ? AND kind = ? AND start_time = ? AND end_time = ?
+			)
+		`, session.Label, string(session.Kind), session.StartTime.Unix(), session.EndTime.Unix()).Scan(&exists)
+		if err != nil {
+			return 0, 0, err
+		}
+		if exists {
+			skipped++
+			continue
 		}
-		defer rows.Close()
+		if _, err := insertSession(tx, &session); err != nil {
+			return 0, 0, err
+		}
+		merged++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return merged, skipped, nil
+}
+
+// ListSessions lists the most recent sessions that are not deleted. If
+// count is 0, it returns all of them.
+func (s *SQLiteStorage) ListSessions(count int) ([]Session, error) {
+	return s.QuerySessions(SessionQuery{Limit: count})
+}
+
+// OverlappingSessions lists the sessions that share any time with the range
+// from start to end, oldest first.
+func (s *SQLiteStorage) OverlappingSessions(start time.Time, end time.Time) ([]Session, error) {
+	return s.selectSessions(`
+		SELECT `+sessionColumns+`
+		FROM sessions
+		WHERE deleted_at IS NULL AND start_time < ? AND end_time > ?
+		ORDER BY start_time ASC
+	`, end.Unix(), start.Unix())
+}
+
+// GetSession returns the session with the given ID, deleted or not.
+func (s *SQLiteStorage) GetSession(id int) (*Session, error) {
+	sessions, err := s.selectSessions(`SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrSessionNotFound, id)
+	}
+	return &sessions[0], nil
+}
+
+// run a query selecting sessionColumns and fill in the pauses and tags of
+// the sessions it returns
+func (s *SQLiteStorage) selectSessions(query string, args ...any) ([]Session, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions, err := scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := s.loadPauses(sessions); err != nil {
+		return nil, err
 	}
+	if err := s.loadTags(sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
 
+// read sessions selected as sessionColumns
+func scanSessions(rows *sql.Rows) ([]Session, error) {
 	var sessions []Session
 	for rows.Next() {
 		var session Session
-		var startUnix, endUnix int64
-		if err := rows.Scan(&session.ID, &session.Label, &startUnix, &endUnix); err != nil {
+		var kind, status string
+		var plannedSeconds, startUnix, endUnix int64
+		var deletedUnix sql.NullInt64
+		if err := rows.Scan(&session.ID, &session.Label, &kind, &status, &plannedSeconds, &startUnix, &endUnix, &session.Note, &deletedUnix); err != nil {
 			return nil, err
 		}
+		session.Kind = SessionKind(kind)
+		session.Status = SessionStatus(status)
+		session.PlannedDuration = time.Duration(plannedSeconds) * time.Second
 		session.StartTime = time.Unix(startUnix, 0)
 		session.EndTime = time.Unix(endUnix, 0)
+		if deletedUnix.Valid {
+			session.DeletedAt = time.Unix(deletedUnix.Int64, 0)
+		}
 		sessions = append(sessions, session)
 	}
-	return sessions, nil
+	return sessions, rows.Err()
 }
 
-// STATS
-func (s *SQLiteStorage) ComputePomoStats(timeframe string) (*PomoStats, error) {
-	statsTimeFrame, err := resolveTimeFrame(timeframe)
-	if err != nil {
-		return nil, err
+// UpdateSession saves the edited fields and tags of a session that is not
+// deleted.
+// Pauses that no longer fall within the session are dropped and the ones
+// that straddle its new start or end are cut to fit.
+func (s *SQLiteStorage) UpdateSession(session *Session) error {
+	if !session.EndTime.After(session.StartTime) {
+		return fmt.Errorf("the session must end after it starts")
 	}
 
-	stats := &PomoStats{}
-	stats.TotalWorkDuration, _ = computeTotalWorkDurationStats(statsTimeFrame, s.db)
-	stats.TotalSessions, _ = computeTotalSessions(statsTimeFrame, s.db)
-	stats.AverageSessionDuration, _ = computeAverageSessionDuration(statsTimeFrame, s.db)
-	stats.LongestSession, _ = computeLongestSession(statsTimeFrame, s.db)
-	stats.ShortestSession, _ = computeShortestSession(statsTimeFrame, s.db)
-	stats.HighestSessionLabel, _ = computeHighestSessionLabel(statsTimeFrame, s.db)
-	stats.TimeSpentPerLabel, _ = computeTimeSpentPerLabel(statsTimeFrame, s.db)
-	stats.PomosPerLabel, _ = computePomosPerLabel(statsTimeFrame, s.db)
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	start, end := session.StartTime.Unix(), session.EndTime.Unix()
+	result, err := tx.Exec(`
+		UPDATE sessions
+		SET label = ?, note = ?, kind = ?, status = ?, planned_seconds = ?, start_time = ?, end_time = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, session.Label, session.Note, string(session.Kind), string(session.Status), int64(session.PlannedDuration.Seconds()),
+		start, end, session.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %d", ErrSessionNotFound, session.ID)
+	}
 
+	_, err = tx.Exec(`
+		DELETE FROM pauses
+		WHERE session_id = ? AND (end_time <= ? OR start_time >= ?)
+	`, session.ID, start, end)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE pauses
+		SET start_time = MAX(start_time, ?), end_time = MIN(end_time, ?)
+		WHERE session_id = ?
+	`, start, end, session.ID)
+	if err != nil {
+		return err
+	}
 
-	return stats, nil
+	if _, err := tx.Exec(`DELETE FROM session_tags WHERE session_id = ?`, session.ID); err != nil {
+		return err
+	}
+	if err := insertTags(tx, int64(session.ID), session.Tags); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// resolve time frame
+// DeleteSession marks a session as deleted. Deleted sessions are left out
+// of every listing and statistic until they are restored.
+func (s *SQLiteStorage) DeleteSession(id int) error {
+	result, err := s.db.Exec(`
+		UPDATE sessions SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL
+	`, time.Now().Unix(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w: %d", ErrSessionNotFound, id)
+	}
+	return nil
+}
 
-func resolveTimeFrame(timeframe string) (TimeFrame, error) {
-	now := time.Now()
-	statsTimeFrame := TimeFrame{}
+// RestoreSession undoes the deletion of a session.
+func (s *SQLiteStorage) RestoreSession(id int) error {
+	result, err := s.db.Exec(`
+		UPDATE sessions SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("%w among the deleted sessions: %d", ErrSessionNotFound, id)
+	}
+	return nil
+}
 
-	switch timeframe {
-	case "all":
-		statsTimeFrame.start = time.Time{}
-		statsTimeFrame.end = now
+// LabelUsage is a label together with how much it has been used.
+type LabelUsage struct {
+	Label        string
+	Sessions     int
+	WorkDuration time.Duration
+	LastUsed     time.Time
+}
 
-	case "today":
-		statsTimeFrame.start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		statsTimeFrame.end = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, int(time.Second-time.Nanosecond), now.Location())
+// ListLabels lists every label of the sessions that are not deleted, the
+// most used first.
+func (s *SQLiteStorage) ListLabels() ([]LabelUsage, error) {
+	rows, err := s.db.Query(`
+		SELECT label, COUNT(*),
+			COALESCE(SUM(CASE WHEN kind = 'work' THEN ` + netDurationSQL + ` ELSE 0 END), 0),
+			MAX(start_time)
+		FROM sessions
+		WHERE deleted_at IS NULL
+		GROUP BY label
+		ORDER BY COUNT(*) DESC, label ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	case "week":
-		weekday := int(now.Weekday())
-		if weekday == 0 { // Sunday → make it 7
-			weekday = 7
+	var labels []LabelUsage
+	for rows.Next() {
+		var usage LabelUsage
+		var workSeconds, lastUnix int64
+		if err := rows.Scan(&usage.Label, &usage.Sessions, &workSeconds, &lastUnix); err != nil {
+			return nil, err
 		}
-		startOfWeek := now.AddDate(0, 0, -(weekday - 1)) // Monday
-		statsTimeFrame.start = time.Date(startOfWeek.Year(), startOfWeek.Month(), startOfWeek.Day(), 0, 0, 0, 0, now.Location())
-		statsTimeFrame.end = statsTimeFrame.start.AddDate(0, 0, 7).Add(-time.Nanosecond)
-
-	case "month":
-		statsTimeFrame.start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		statsTimeFrame.end = statsTimeFrame.start.AddDate(0, 1, 0).Add(-time.Nanosecond)
-
-	case "year":
-		statsTimeFrame.start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-		statsTimeFrame.end = statsTimeFrame.start.AddDate(1, 0, 0).Add(-time.Nanosecond)
-
-	default:
-		return TimeFrame{}, fmt.Errorf("invalid timeframe: %s", timeframe)
+		usage.WorkDuration = time.Duration(workSeconds) * time.Second
+		usage.LastUsed = time.Unix(lastUnix, 0)
+		labels = append(labels, usage)
 	}
-
-	return statsTimeFrame, nil
+	return labels, rows.Err()
 }
 
-// compute total work duration stats
-func computeTotalWorkDurationStats(timeframe TimeFrame, db *sql.DB) (time.Duration, error) {
-	query := `
-		SELECT SUM(end_time - start_time)
-		FROM sessions
-		WHERE start_time BETWEEN ? AND ?
-	`
+// RelabelSessions gives every session labelled with one of from the label
+// to, deleted sessions included, in a single transaction. It returns how
+// many sessions each label of from had. With dryRun the changes are rolled
+// back, so only the counts are reported.
+//
+// The goals of the labels in from move to to as well. Where to ends up with
+// two goals for the same period, the one it had already is kept, or else
+// the one of the label listed first.
+func (s *SQLiteStorage) RelabelSessions(from []string, to string, dryRun bool) (map[string]int, error) {
+	if to == "" {
+		return nil, fmt.Errorf("the new label must not be empty")
+	}
 
-	var totalSeconds sql.NullInt64
-	err := db.QueryRow(query, timeframe.start.Unix(), timeframe.end.Unix()).Scan(&totalSeconds)
+	tx, err := s.db.Begin()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	if !totalSeconds.Valid {
-		return 0, nil
+	counts := make(map[string]int, len(from))
+	for _, label := range from {
+		// a label given twice was moved the first time, its count stands
+		if _, seen := counts[label]; seen {
+			continue
+		}
+		if label == to {
+			counts[label] = 0
+			continue
+		}
+		result, err := tx.Exec(`UPDATE sessions SET label = ? WHERE label = ?`, to, label)
+		if err != nil {
+			return nil, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		counts[label] = int(n)
+		if err := relabelGoals(tx, label, to); err != nil {
+			return nil, err
+		}
 	}
 
-
-	return time.Duration(totalSeconds.Int64) * time.Second, nil
+	if dryRun {
+		return counts, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return counts, nil
 }
 
-func computeTotalSessions(timeframe TimeFrame, db *sql.DB) (int, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM sessions
-		WHERE start_time BETWEEN ? AND ?
-	`
-	var count int
-	err := db.QueryRow(query, timeframe.start.Unix(), timeframe.end.Unix()).Scan(&count)
-	if err != nil {
-		return 0, err
+// move the goals of the label from to the label to, dropping those of a
+// period that to has a goal for already
+func relabelGoals(tx *sql.Tx, from, to string) error {
+	// the goals over every label have no label to move
+	if from == "" {
+		return nil
 	}
-	return count, nil
+	if _, err := tx.Exec(`
+		DELETE FROM goals
+		WHERE label = ? AND period IN (SELECT period FROM goals WHERE label = ?)
+	`, from, to); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`UPDATE goals SET label = ? WHERE label = ?`, to, from)
+	return err
 }
 
-func computeAverageSessionDuration(timeframe TimeFrame, db *sql.DB) (time.Duration, error) {
-	query := `
-		SELECT AVG(end_time - start_time)
-		FROM sessions
-		WHERE start_time BETWEEN ? AND ?
-	`
-	var avgSeconds sql.NullFloat64
-	err := db.QueryRow(query, timeframe.start.Unix(), timeframe.end.Unix()).Scan(&avgSeconds)
-	if err != nil {
-		return 0, err
+// fill in the pauses of the given sessions
+func (s *SQLiteStorage) loadPauses(sessions []Session) error {
+	if len(sessions) == 0 {
+		return nil
 	}
-	if !avgSeconds.Valid {
-		return 0, nil
+
+	index := make(map[int]int, len(sessions))
+	placeholders := make([]string, 0, len(sessions))
+	args := make([]any, 0, len(sessions))
+	for i, session := range sessions {
+		index[session.ID] = i
+		placeholders = append(placeholders, "?")
+		args = append(args, session.ID)
 	}
-	return time.Duration(avgSeconds.Float64) * time.Second, nil
-}
 
-func computeLongestSession(timeframe TimeFrame, db *sql.DB) (time.Duration, error) {
-	query := `SELECT MAX(end_time - start_time)
-		FROM sessions
-		WHERE start_time BETWEEN ? AND ?`
-	var longestSeconds sql.NullInt64
-	err := db.QueryRow(query, timeframe.start.Unix(), timeframe.end.Unix()).Scan(&longestSeconds)
+	rows, err := s.db.Query(`
+		SELECT session_id, start_time, end_time
+		FROM pauses
+		WHERE session_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY start_time ASC
+	`, args...)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	if !longestSeconds.Valid {
-		return 0, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID int
+		var startUnix, endUnix int64
+		if err := rows.Scan(&sessionID, &startUnix, &endUnix); err != nil {
+			return err
+		}
+		i := index[sessionID]
+		sessions[i].Pauses = append(sessions[i].Pauses, Pause{
+			StartTime: time.Unix(startUnix, 0),
+			EndTime:   time.Unix(endUnix, 0),
+		})
 	}
-	return time.Duration(longestSeconds.Int64) * time.Second, nil
+	return rows.Err()
 }
 
-func computeShortestSession(timeframe TimeFrame, db *sql.DB) (time.Duration, error) {
-	query := `SELECT MIN(end_time - start_time) as shortest
-		FROM sessions
-		WHERE start_time BETWEEN ? AND ? 
-		GROUP BY label
-		ORDER BY shortest ASC
-		LIMIT 1`
-	var shortestSeconds sql.NullInt64
-	err := db.QueryRow(query, timeframe.start.Unix(), timeframe.end.Unix()).Scan(&shortestSeconds)
+// fill in the tags of the given sessions, in alphabetical order
+func (s *SQLiteStorage) loadTags(sessions []Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(sessions))
+	placeholders := make([]string, 0, len(sessions))
+	args := make([]any, 0, len(sessions))
+	for i, session := range sessions {
+		index[session.ID] = i
+		placeholders = append(placeholders, "?")
+		args = append(args, session.ID)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT session_id, tag
+		FROM session_tags
+		WHERE session_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY tag ASC
+	`, args...)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	if !shortestSeconds.Valid {
-		return 0, nil
+	defer rows.Close()
+
+	for rows.Next() {
+		var sessionID int
+		var tag string
+		if err := rows.Scan(&sessionID, &tag); err != nil {
+			return err
+		}
+		i := index[sessionID]
+		sessions[i].Tags = append(sessions[i].Tags, tag)
 	}
-	return time.Duration(shortestSeconds.Int64) * time.Second, nil
+	return rows.Err()
 }
 
-func computeHighestSessionLabel(timeframe TimeFrame, db *sql.DB) (map[string]time.Duration, error) {
-	query := `SELECT label, MAX(end_time - start_time) as longest
-		FROM sessions
-		WHERE start_time BETWEEN ? AND ?
-		GROUP BY label
-		ORDER BY longest DESC
-		LIMIT 1`
-	highestSessionLabel := make(map[string]time.Duration)
-	var label string
-	var longestSeconds sql.NullInt64
-	err := db.QueryRow(query, timeframe.start.Unix(), timeframe.end.Unix()).Scan(&label, &longestSeconds)
+// STATS
+
+// ComputePomoStats computes the statistics of a time frame, see PomoStats.
+func (s *SQLiteStorage) ComputePomoStats(statsTimeFrame TimeFrame) (*PomoStats, error) {
+	// one read transaction, so that every number comes from the same data
+	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
-	if !longestSeconds.Valid {
-		return nil, nil
+	defer tx.Rollback()
+
+	stats := &PomoStats{TimeFrame: statsTimeFrame}
+	if err := computeTimeSpent(tx, statsTimeFrame, stats); err != nil {
+		return nil, fmt.Errorf("computing time spent: %w", err)
+	}
+	if err := computeSessionStats(tx, statsTimeFrame, stats); err != nil {
+		return nil, fmt.Errorf("computing session stats: %w", err)
 	}
-	highestSessionLabel[label] = time.Duration(longestSeconds.Int64) * time.Second
-	return highestSessionLabel, nil
+	return stats, tx.Commit()
 }
-func computeTimeSpentPerLabel(timeframe TimeFrame, db *sql.DB) (map[string]time.Duration, error) {
-	query := `SELECT label, SUM(end_time - start_time)
+
+// clippedNetDurationSQL is netDurationSQL for the part of a session between
+// ?1 and ?2
+const clippedNetDurationSQL = `(MIN(end_time, ?2) - MAX(start_time, ?1) - COALESCE(
+	(SELECT SUM(MAX(0, MIN(p.end_time, ?2) - MAX(p.start_time, ?1))) FROM pauses p WHERE p.session_id = sessions.id), 0))`
+
+// compute the focused time of work and of breaks in the time frame, and of
+// work per label
+func computeTimeSpent(tx *sql.Tx, timeframe TimeFrame, stats *PomoStats) error {
+	start, end := timeframe.bounds()
+	rows, err := tx.Query(`
+		SELECT kind, label, SUM(`+clippedNetDurationSQL+`)
 		FROM sessions
-		WHERE start_time BETWEEN ? AND ?
-		GROUP BY label`
-	timeSpentPerLabel := make(map[string]time.Duration)
-	rows, err := db.Query(query, timeframe.start.Unix(), timeframe.end.Unix())
+		WHERE deleted_at IS NULL AND start_time < ?2 AND end_time > ?1
+		GROUP BY kind, label
+	`, start, end)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
+
+	stats.TimeSpentPerLabel = make(map[string]time.Duration)
 	for rows.Next() {
+		var kind SessionKind
 		var label string
-		var duration sql.NullInt64
-		if err := rows.Scan(&label, &duration); err != nil {
-			return nil, err
+		var totalSeconds int64
+		if err := rows.Scan(&kind, &label, &totalSeconds); err != nil {
+			return err
 		}
-		if !duration.Valid {
+		d := time.Duration(totalSeconds) * time.Second
+		if kind.IsBreak() {
+			stats.TotalBreakDuration += d
 			continue
 		}
-		timeSpentPerLabel[label] = time.Duration(duration.Int64) * time.Second
+		stats.TotalWorkDuration += d
+		stats.TimeSpentPerLabel[label] += d
 	}
-	return timeSpentPerLabel, nil
+	return rows.Err()
 }
 
-func computePomosPerLabel(timeframe TimeFrame, db *sql.DB) (map[string]int, error) {
-	query := `SELECT label, COUNT(*)
+// compute the metrics of the work sessions that started in the time frame
+func computeSessionStats(tx *sql.Tx, timeframe TimeFrame, stats *PomoStats) error {
+	start, end := timeframe.bounds()
+	rows, err := tx.Query(`
+		SELECT label, status, planned_seconds, `+netDurationSQL+`
 		FROM sessions
-		WHERE start_time BETWEEN ? AND ?
-		GROUP BY label`
-	pomosPerLabel := make(map[string]int)
-	rows, err := db.Query(query, timeframe.start.Unix(), timeframe.end.Unix())
+		WHERE kind = 'work' AND deleted_at IS NULL AND start_time >= ? AND start_time < ?
+	`, start, end)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
+
+	stats.PomosPerLabel = make(map[string]int)
+	stats.PlannedAchievedPerLabel = make(map[string]float64)
+	plannedSessions := make(map[string]int)
+	var durations []time.Duration
+	var total time.Duration
+	completed := 0
 	for rows.Next() {
 		var label string
-		var count int
-		if err := rows.Scan(&label, &count); err != nil {
-			return nil, err
+		var status SessionStatus
+		var plannedSeconds, netSeconds int64
+		if err := rows.Scan(&label, &status, &plannedSeconds, &netSeconds); err != nil {
+			return err
+		}
+		d := time.Duration(netSeconds) * time.Second
+		durations = append(durations, d)
+		total += d
+		stats.PomosPerLabel[label]++
+		if status == StatusCompleted {
+			completed++
+		}
+		if d > stats.LongestSession || len(durations) == 1 {
+			stats.LongestSession, stats.LongestSessionLabel = d, label
+		}
+		// sessions saved without a planned duration are left out
+		if plannedSeconds > 0 {
+			stats.PlannedAchievedPerLabel[label] += math.Min(1, float64(netSeconds)/float64(plannedSeconds))
+			plannedSessions[label]++
 		}
-		pomosPerLabel[label] = count
 	}
-	return pomosPerLabel, nil
-}
-
-// INITIAL SETUP
-
-// create table
-func initTable(db *sql.DB) error {
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS sessions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			label TEXT NOT NULL,
-			start_time INTEGER NOT NULL,
-			end_time INTEGER NOT NULL
-		)
-	`)
-	if err != nil {
+	if err := rows.Err(); err != nil {
 		return err
 	}
+
+	for label, n := range plannedSessions {
+		stats.PlannedAchievedPerLabel[label] /= float64(n)
+	}
+	stats.TotalSessions = len(durations)
+	if len(durations) == 0 {
+		return nil
+	}
+	stats.AverageSessionDuration = (total / time.Duration(len(durations))).Truncate(time.Second)
+	stats.CompletionRate = float64(completed) / float64(len(durations))
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.ShortestSession = durations[0]
+	stats.MedianSession = percentile(durations, 50)
+	stats.SessionPercentiles = map[int]time.Duration{
+		25: percentile(durations, 25),
+		75: percentile(durations, 75),
+		90: percentile(durations, 90),
+	}
 	return nil
 }
+
+// the p-th percentile of sorted durations, interpolating between the two
+// closest ranks and rounding to the second
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	fraction := rank - float64(lower)
+	d := sorted[lower] + time.Duration(fraction*float64(sorted[lower+1]-sorted[lower]))
+	return d.Round(time.Second)
+}
@@ -0,0 +1,140 @@
+package timer
+
+// Snapshot of a running timer that other processes can read
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State describes what the timer is doing right now.
+type State struct {
+	Running          bool        `json:"running"`
+	Paused           bool        `json:"paused"`
+	Label            string      `json:"label"`
+	Phase            SessionKind `json:"phase"`
+	Description      string      `json:"description"`
+	Round            int         `json:"round"`
+	Position         string      `json:"position"`
+	RemainingSeconds int64       `json:"remaining_seconds"`
+	PlannedSeconds   int64       `json:"planned_seconds"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	// PID is the process running the timer, set when the state is shared
+	// through a state file, and PIDStart tells it apart from a later
+	// process with the same pid where the system allows.
+	PID      int    `json:"pid,omitempty"`
+	PIDStart string `json:"pid_start,omitempty"`
+
+	// IntervalStart and Pauses describe the current interval so far, and
+	// the settings below the timer running it, so an interrupted timer can
+	// be resumed from a checkpoint. Cycle is the number of work intervals
+	// the timer completed, which a break after a skipped one leaves out.
+	IntervalStart    time.Time `json:"interval_start"`
+	Pauses           []Pause   `json:"pauses,omitempty"`
+	Cycle            int       `json:"cycle,omitempty"`
+	WorkSeconds      int64     `json:"work_seconds,omitempty"`
+	BreakSeconds     int64     `json:"break_seconds,omitempty"`
+	LongBreakSeconds int64     `json:"long_break_seconds,omitempty"`
+	LongEvery        int       `json:"long_every,omitempty"`
+	Tags             []string  `json:"tags,omitempty"`
+}
+
+// Remaining is the time left in the current interval.
+func (s State) Remaining() time.Duration {
+	return time.Duration(s.RemainingSeconds) * time.Second
+}
+
+// Clock is the time left in the current interval as mm:ss.
+func (s State) Clock() string {
+	return fmt.Sprintf("%02d:%02d", int(s.Remaining().Minutes()), int(s.Remaining().Seconds())%60)
+}
+
+// Planned is the planned duration of the current interval.
+func (s State) Planned() time.Duration {
+	return time.Duration(s.PlannedSeconds) * time.Second
+}
+
+// Alive reports whether the process that wrote the state is still running,
+// rather than another process that got its pid, e.g. after a reboot.
+func (s State) Alive() bool {
+	if !processAlive(s.PID) {
+		return false
+	}
+	if s.PIDStart == "" {
+		return true
+	}
+	start, ok := processStart(s.PID)
+	return !ok || start == s.PIDStart
+}
+
+// Interrupted reports whether the state was checkpointed by a timer whose
+// process died before it could stop cleanly, in the middle of an interval.
+// An interval with nothing left was already saved when it finished.
+func (s State) Interrupted() bool {
+	return s.Running && s.RemainingSeconds > 0 && s.PID != 0 && !s.Alive()
+}
+
+// RecoveredSession is the interrupted interval as it stood at the last
+// checkpoint, saved as aborted.
+func (s State) RecoveredSession() Session {
+	pauses := append([]Pause(nil), s.Pauses...)
+	if n := len(pauses); n > 0 && pauses[n-1].EndTime.IsZero() {
+		pauses[n-1].EndTime = s.UpdatedAt
+	}
+	return Session{
+		Kind:            s.Phase,
+		Status:          StatusAborted,
+		Label:           s.Label,
+		Tags:            s.Tags,
+		PlannedDuration: s.Planned(),
+		StartTime:       s.IntervalStart,
+		EndTime:         s.UpdatedAt,
+		Pauses:          pauses,
+	}
+}
+
+// StateTracker is an Observer that keeps the State of the timer it is
+// subscribed to up to date. It is safe to read from other goroutines.
+type StateTracker struct {
+	mu    sync.Mutex
+	state State
+}
+
+func (t *StateTracker) OnEvent(event Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// a finished interval is saved, there is nothing running until the
+	// next one starts
+	switch event.Type {
+	case IntervalCompleted, IntervalSkipped, Aborted:
+		t.state.Running = false
+	default:
+		t.state.Running = true
+	}
+	t.state.Label = event.Label
+	t.state.Phase = event.Kind
+	t.state.Description = event.Description
+	t.state.Round = event.Round
+	t.state.Position = event.Position
+	t.state.RemainingSeconds = int64(event.Remaining.Seconds())
+	t.state.PlannedSeconds = int64(event.Planned.Seconds())
+	t.state.UpdatedAt = event.Time
+	t.state.IntervalStart = event.IntervalStart
+	t.state.Pauses = event.Pauses
+
+	switch event.Type {
+	case Paused:
+		t.state.Paused = true
+	case IntervalStarted, Resumed, Aborted:
+		t.state.Paused = false
+	}
+}
+
+// State returns a copy of the latest state.
+func (t *StateTracker) State() State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
@@ -0,0 +1,146 @@
+package timer
+
+// Sharing the state of a foreground timer through a file
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// ErrCorruptStateFile is returned for a state file that cannot be decoded,
+// e.g. one cut short by a crash.
+var ErrCorruptStateFile = errors.New("corrupt state file")
+
+// StateFile is an Observer that writes the timer State to a JSON file after
+// every event, so other processes can tell what the timer is doing. With a
+// tick every second, the file doubles as a checkpoint to resume the timer
+// from if its process dies.
+type StateFile struct {
+	Path     string
+	pt       *PomodoroTimer
+	tracker  StateTracker
+	pidStart string
+}
+
+func NewStateFile(path string, pt *PomodoroTimer) *StateFile {
+	pidStart, _ := processStart(os.Getpid())
+	return &StateFile{Path: path, pt: pt, pidStart: pidStart}
+}
+
+func (f *StateFile) OnEvent(event Event) {
+	f.tracker.OnEvent(event)
+	state := f.tracker.State()
+	state.PID = os.Getpid()
+	state.PIDStart = f.pidStart
+	state.Cycle = f.pt.Cycle
+	state.WorkSeconds = int64(f.pt.WorkDuration.Seconds())
+	state.BreakSeconds = int64(f.pt.BreakDuration.Seconds())
+	state.LongBreakSeconds = int64(f.pt.LongBreakDuration.Seconds())
+	state.LongEvery = f.pt.LongBreakEvery
+	state.Tags = f.pt.Tags
+	// the state file is best effort, a failed write must not stop the timer.
+	// Only transitions are flushed to disk, a lost tick costs a second.
+	_ = writeStateFile(f.Path, state, event.Type != Tick)
+}
+
+// DefaultStatePath is the state file in $XDG_STATE_HOME, falling back to
+// ~/.local/state.
+func DefaultStatePath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "pomolite-state.json")
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "pomolite", "state.json")
+}
+
+// WriteStateFile replaces the state file atomically, creating its directory
+// if needed, and flushes it to disk.
+func WriteStateFile(path string, state State) error {
+	return writeStateFile(path, state, true)
+}
+
+// replace the state file atomically, flushing it to disk before the rename
+// if sync is set, or a crash can leave the renamed file empty
+func writeStateFile(path string, state State, sync bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if sync {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadStateFile loads the state file. A missing file is an idle timer, and
+// one that cannot be decoded is an ErrCorruptStateFile.
+func ReadStateFile(path string) (State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("%w %s: %v", ErrCorruptStateFile, path, err)
+	}
+	return state, nil
+}
+
+// MoveCorruptStateFile moves a state file that cannot be read out of the
+// way, to path.corrupt, and returns where it went.
+func MoveCorruptStateFile(path string) (string, error) {
+	corrupt := path + ".corrupt"
+	return corrupt, os.Rename(path, corrupt)
+}
+
+// RemoveStateFile deletes the state file, if there is one.
+func RemoveStateFile(path string) error {
+	err := os.Remove(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// processAlive reports whether a process with the given pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
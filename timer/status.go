@@ -0,0 +1,153 @@
+package timer
+
+// The state of the timer for status bars and scripts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// StatusView is the data available to status templates.
+type StatusView struct {
+	Running          bool
+	Paused           bool
+	Label            string
+	Phase            string
+	Description      string
+	Remaining        string
+	RemainingSeconds int64
+	Cycle            int
+	Position         string
+	Percent          int
+	Goals            []GoalProgress
+}
+
+// NewStatusView is the view of the state with the progress of the goals.
+func NewStatusView(state State, goals []GoalProgress) StatusView {
+	view := StatusView{
+		Running:          state.Running,
+		Paused:           state.Paused,
+		Label:            state.Label,
+		Phase:            string(state.Phase),
+		Description:      state.Description,
+		Remaining:        state.Clock(),
+		RemainingSeconds: state.RemainingSeconds,
+		Cycle:            state.Round,
+		Position:         state.Position,
+		Goals:            goals,
+	}
+	if state.PlannedSeconds > 0 {
+		view.Percent = int(100 * (state.PlannedSeconds - state.RemainingSeconds) / state.PlannedSeconds)
+	}
+	return view
+}
+
+// WriteStatus writes the state and the progress of the goals in a format:
+// json, waybar, tmux, which leaves the goals out, or otherwise a Go
+// text/template over a StatusView, followed by a newline.
+func WriteStatus(w io.Writer, format string, state State, goals []GoalProgress) error {
+	switch format {
+	case "json":
+		return writeStatusJSON(w, state, goals)
+	case "waybar":
+		return writeStatusWaybar(w, state, goals)
+	case "tmux":
+		return writeStatusTmux(w, state)
+	}
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	if err := tmpl.Execute(w, NewStatusView(state, goals)); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w)
+	return err
+}
+
+func writeStatusJSON(w io.Writer, state State, goals []GoalProgress) error {
+	if goals == nil {
+		goals = []GoalProgress{}
+	}
+	data, err := json.Marshal(struct {
+		State
+		Goals []GoalProgress `json:"goals"`
+	}{state, goals})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func writeStatusWaybar(w io.Writer, state State, goals []GoalProgress) error {
+	module := struct {
+		Text       string `json:"text"`
+		Tooltip    string `json:"tooltip"`
+		Class      string `json:"class"`
+		Percentage int    `json:"percentage"`
+	}{Class: "idle"}
+
+	if state.Running {
+		icon := "🍅"
+		if state.Phase.IsBreak() {
+			icon = "☕"
+		}
+		module.Text = fmt.Sprintf("%s %s", icon, state.Clock())
+		module.Tooltip = fmt.Sprintf("%s (%s)", state.Description, state.Phase)
+		module.Class = string(state.Phase)
+		module.Percentage = NewStatusView(state, nil).Percent
+		if state.Paused {
+			module.Text = fmt.Sprintf("⏸ %s", state.Clock())
+			module.Class = "paused"
+		}
+	}
+	for _, p := range goals {
+		if module.Tooltip != "" {
+			module.Tooltip += "\n"
+		}
+		module.Tooltip += fmt.Sprintf("🎯 %s: %s", p.Goal.Name(), p.Progress())
+	}
+
+	data, err := json.Marshal(module)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func writeStatusTmux(w io.Writer, state State) error {
+	var err error
+	switch {
+	case !state.Running:
+		_, err = fmt.Fprintln(w)
+	case state.Paused:
+		_, err = fmt.Fprintf(w, "#[fg=yellow]⏸ %s#[default]\n", state.Clock())
+	case state.Phase.IsBreak():
+		_, err = fmt.Fprintf(w, "#[fg=green]☕ %s#[default]\n", state.Clock())
+	default:
+		_, err = fmt.Fprintf(w, "#[fg=red]🍅 %s %s#[default]\n", state.Label, state.Clock())
+	}
+	return err
+}
+
+// ReadLiveState reads the state file as other processes should see it: a
+// corrupt file, which 'pomo start' moves out of the way, is an idle timer,
+// and a timer whose process died is not running.
+func ReadLiveState(path string) (State, error) {
+	state, err := ReadStateFile(path)
+	if errors.Is(err, ErrCorruptStateFile) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	if state.Running && !state.Alive() {
+		state.Running = false
+	}
+	return state, nil
+}
@@ -3,31 +3,133 @@ package timer
 // Storage interface for the timer using sqlite
 
 import (
+	"errors"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type Storage interface {
-	SaveTimerData(label string, startTime time.Time, endTime time.Time) error
+	SaveTimerData(session *Session) error
 	ListSessions(count int) ([]Session, error)
+	// UpdateSession overwrites the label, note, kind, status, planned
+	// duration and times of the session with the same ID.
+	UpdateSession(session *Session) error
+	// DeleteSession hides a session from listings and stats until it is
+	// restored.
+	DeleteSession(id int) error
+	RestoreSession(id int) error
 	Close() error
 }
 
+// ErrSessionNotFound is returned for an ID that matches no session.
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionKind tells work intervals apart from breaks.
+type SessionKind string
+
+const (
+	KindWork       SessionKind = "work"
+	KindShortBreak SessionKind = "short_break"
+	KindLongBreak  SessionKind = "long_break"
+)
+
+// IsBreak reports whether the kind is a short or long break.
+func (k SessionKind) IsBreak() bool {
+	return k == KindShortBreak || k == KindLongBreak
+}
+
+// SessionStatus records how an interval ended.
+type SessionStatus string
+
+const (
+	StatusCompleted SessionStatus = "completed"
+	StatusAborted   SessionStatus = "aborted"
+	StatusSkipped   SessionStatus = "skipped"
+)
+
 type Session struct {
-	ID        int
-	Label     string
-	StartTime time.Time
-	EndTime   time.Time
+	ID              int
+	Kind            SessionKind
+	Status          SessionStatus
+	Label           string
+	PlannedDuration time.Duration
+	StartTime       time.Time
+	EndTime         time.Time
+	Pauses          []Pause
+	Tags            []string
+	Note            string
+	// DeletedAt is when the session was deleted, zero unless it was.
+	DeletedAt time.Time
 }
 
+// Pause is a stretch of time during a session when the timer was paused.
+type Pause struct {
+	StartTime time.Time `json:"start_time" yaml:"start_time"`
+	EndTime   time.Time `json:"end_time" yaml:"end_time"`
+}
+
+// Deleted reports whether the session was deleted.
+func (s Session) Deleted() bool {
+	return !s.DeletedAt.IsZero()
+}
+
+// Duration is the gross duration of the session, pauses included.
+func (s Session) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// PausedDuration is the total time the session spent paused.
+func (s Session) PausedDuration() time.Duration {
+	var paused time.Duration
+	for _, p := range s.Pauses {
+		paused += p.EndTime.Sub(p.StartTime)
+	}
+	return paused
+}
+
+// NetDuration is the focused time of the session, pauses excluded.
+func (s Session) NetDuration() time.Duration {
+	return s.Duration() - s.PausedDuration()
+}
+
+// PomoStats are the statistics of a time frame. The time totals count the
+// focused time inside the frame, clipping sessions that run across its
+// start or end. The metrics of sessions, their number, lengths and
+// completion, cover the work sessions that started in the frame at their
+// full length, so every session belongs to exactly one frame.
 type PomoStats struct {
-	TotalWorkDuration time.Duration
-	TotalSessions int
+	// TimeFrame is the range the statistics cover.
+	TimeFrame TimeFrame
+
+	TotalWorkDuration  time.Duration
+	TotalBreakDuration time.Duration
+	TimeSpentPerLabel  map[string]time.Duration
+
+	TotalSessions          int
 	AverageSessionDuration time.Duration
-	LongestSession time.Duration
-	ShortestSession time.Duration
-	HighestSessionLabel map[string]time.Duration
-	TimeSpentPerLabel map[string]time.Duration
-	PomosPerLabel map[string]int
+	LongestSession         time.Duration
+	LongestSessionLabel    string
+	ShortestSession        time.Duration
+	MedianSession          time.Duration
+	// SessionPercentiles are the session lengths at the 25th, 75th and
+	// 90th percentile.
+	SessionPercentiles map[int]time.Duration
+	PomosPerLabel      map[string]int
+
+	// CompletionRate is the share of work intervals that ran to completion.
+	CompletionRate float64
+	// PlannedAchievedPerLabel is the average fraction of the planned duration
+	// that was actually worked, per label.
+	PlannedAchievedPerLabel map[string]float64
+
+	// Goals is the progress of the goals in the current day and week,
+	// whatever the time frame.
+	Goals []GoalProgress
+	// Streaks are the streaks over every label and of each label, up to
+	// today, whatever the time frame.
+	Streaks []Streak
+	// Distribution is the focused time by hour or by weekday, when asked
+	// for.
+	Distribution *Distribution
 }
@@ -0,0 +1,232 @@
+package timer
+
+// Runs of consecutive productive days
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DayBoundary decides which day a moment belongs to. Days are calendar days
+// in Location that start at Hour instead of midnight, so that with Hour 4 a
+// session at 1am still counts toward the evening before.
+type DayBoundary struct {
+	Location *time.Location
+	Hour     int
+}
+
+// Day is the day t belongs to, as midnight of its date in the boundary's
+// location.
+func (b DayBoundary) Day(t time.Time) time.Time {
+	loc := b.location()
+	t = t.In(loc).Add(-time.Duration(b.Hour) * time.Hour)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// the location of the days, the local one unless set
+func (b DayBoundary) location() *time.Location {
+	if b.Location == nil {
+		return time.Local
+	}
+	return b.Location
+}
+
+// Start is the moment a day, as returned by Day, begins.
+func (b DayBoundary) Start(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), b.Hour, 0, 0, 0, day.Location())
+}
+
+// Week is the first day of the week t belongs to, with weeks starting on
+// weekStart.
+func (b DayBoundary) Week(t time.Time, weekStart time.Weekday) time.Time {
+	day := b.Day(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) - int(weekStart) + 7) % 7))
+}
+
+// StreakOptions decide which days keep a streak going: those that reach
+// the daily goal of the label, or without one at least MinPomodoros
+// completed work intervals, which must be at least 1.
+type StreakOptions struct {
+	Boundary     DayBoundary
+	MinPomodoros int
+}
+
+// Streak is the current and the longest run of consecutive qualifying days
+// of a label, or of every label together when Label is empty. A current
+// streak whose last day is yesterday is still alive, as today is not over.
+type Streak struct {
+	Label        string
+	Current      int
+	CurrentStart time.Time
+	Longest      int
+	LongestStart time.Time
+	LongestEnd   time.Time
+}
+
+// dayTotals is the work done in one day
+type dayTotals struct {
+	pomodoros int
+	worked    time.Duration
+}
+
+// ComputeStreaks computes the streak over every label followed by the
+// streak of each label, longest current streak first.
+func (s *SQLiteStorage) ComputeStreaks(opts StreakOptions, now time.Time) ([]Streak, error) {
+	if opts.MinPomodoros < 1 {
+		return nil, fmt.Errorf("the minimum number of pomodoros of a streak day must be at least 1, got %d", opts.MinPomodoros)
+	}
+	goals, err := s.ListGoals()
+	if err != nil {
+		return nil, err
+	}
+	dailyGoals := make(map[string]Goal)
+	for _, goal := range goals {
+		if goal.Period == GoalDaily {
+			dailyGoals[goal.Label] = goal
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT label, status, start_time, ` + netDurationSQL + `
+		FROM sessions
+		WHERE kind = 'work' AND deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// days per label, with "" for every label together
+	days := map[string]map[time.Time]*dayTotals{"": {}}
+	for rows.Next() {
+		var label string
+		var status SessionStatus
+		var startUnix, netSeconds int64
+		if err := rows.Scan(&label, &status, &startUnix, &netSeconds); err != nil {
+			return nil, err
+		}
+		day := opts.Boundary.Day(time.Unix(startUnix, 0))
+		if days[label] == nil {
+			days[label] = make(map[time.Time]*dayTotals)
+		}
+		keys := []string{""}
+		if label != "" {
+			keys = append(keys, label)
+		}
+		for _, key := range keys {
+			totals := days[key][day]
+			if totals == nil {
+				totals = &dayTotals{}
+				days[key][day] = totals
+			}
+			if status == StatusCompleted {
+				totals.pomodoros++
+			}
+			totals.worked += time.Duration(netSeconds) * time.Second
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	today := opts.Boundary.Day(now)
+	streaks := make([]Streak, 0, len(days))
+	for label, perDay := range days {
+		goal, hasGoal := dailyGoals[label]
+		qualifies := func(t dayTotals) bool {
+			if hasGoal {
+				return GoalProgress{Goal: goal, Pomodoros: t.pomodoros, Worked: t.worked}.Done()
+			}
+			return t.pomodoros >= opts.MinPomodoros
+		}
+		var qualifying []time.Time
+		for day, totals := range perDay {
+			if qualifies(*totals) {
+				qualifying = append(qualifying, day)
+			}
+		}
+		streak := longestRun(qualifying, today)
+		streak.Label = label
+		streaks = append(streaks, streak)
+	}
+
+	// keep the streak over every label first
+	sort.Slice(streaks, func(i, j int) bool {
+		a, b := streaks[i], streaks[j]
+		if (a.Label == "") != (b.Label == "") {
+			return a.Label == ""
+		}
+		if a.Current != b.Current {
+			return a.Current > b.Current
+		}
+		if a.Longest != b.Longest {
+			return a.Longest > b.Longest
+		}
+		return a.Label < b.Label
+	})
+	return streaks, nil
+}
+
+// find the longest run of consecutive days, and the run that ends today or
+// yesterday
+func longestRun(days []time.Time, today time.Time) Streak {
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	var streak Streak
+	var runStart time.Time
+	run := 0
+	for i, day := range days {
+		// AddDate rather than 24 hours, as not every day is 24 hours long
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run, runStart = 1, day
+		}
+		if run > streak.Longest {
+			streak.Longest, streak.LongestStart, streak.LongestEnd = run, runStart, day
+		}
+		if day.Equal(today) || day.Equal(today.AddDate(0, 0, -1)) {
+			streak.Current, streak.CurrentStart = run, runStart
+		}
+	}
+	return streak
+}
+
+type streakRecord struct {
+	Label        string  `json:"label" yaml:"label"`
+	Current      int     `json:"current" yaml:"current"`
+	CurrentStart *string `json:"current_start" yaml:"current_start"`
+	Longest      int     `json:"longest" yaml:"longest"`
+	LongestStart *string `json:"longest_start" yaml:"longest_start"`
+	LongestEnd   *string `json:"longest_end" yaml:"longest_end"`
+}
+
+func (s Streak) record() streakRecord {
+	return streakRecord{
+		Label:        s.Label,
+		Current:      s.Current,
+		CurrentStart: dateOrNil(s.CurrentStart),
+		Longest:      s.Longest,
+		LongestStart: dateOrNil(s.LongestStart),
+		LongestEnd:   dateOrNil(s.LongestEnd),
+	}
+}
+
+func (s Streak) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.record())
+}
+
+func (s Streak) MarshalYAML() (any, error) {
+	return s.record(), nil
+}
+
+// the date of t as YYYY-MM-DD, or nil when t is zero
+func dateOrNil(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	date := t.Format("2006-01-02")
+	return &date
+}
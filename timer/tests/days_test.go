@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestDailyWorkTime(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	at := func(day, hour int) time.Time { return time.Date(2025, 9, day, hour, 0, 0, 0, time.Local) }
+	sessions := []timer.Session{
+		{Label: "Coding", Kind: timer.KindWork, StartTime: at(1, 9), EndTime: at(1, 10)},
+		{Label: "Reading", Kind: timer.KindWork, StartTime: at(1, 20), EndTime: at(1, 21)},
+		{Label: "Coding", Kind: timer.KindShortBreak, StartTime: at(1, 10), EndTime: at(1, 11)},
+		// after midnight, the evening before with days starting at 4
+		{Label: "Coding", Kind: timer.KindWork, StartTime: at(3, 1), EndTime: at(3, 2)},
+		// outside the time frame
+		{Label: "Coding", Kind: timer.KindWork, StartTime: at(30, 9), EndTime: at(30, 10)},
+	}
+	for i := range sessions {
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	timeframe := timer.TimeFrame{Start: at(1, 0), End: at(29, 0)}
+
+	totals, err := storage.DailyWorkTime(timeframe, "", timer.DayBoundary{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 2 || !totals[0].Day.Equal(at(1, 0)) || totals[0].Worked != 2*time.Hour || !totals[1].Day.Equal(at(3, 0)) {
+		t.Errorf("expected 2h on the 1st and 1h on the 3rd, got %+v", totals)
+	}
+
+	totals, err = storage.DailyWorkTime(timeframe, "Coding", timer.DayBoundary{Hour: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(totals) != 2 || totals[0].Worked != time.Hour || !totals[1].Day.Equal(at(2, 0)) {
+		t.Errorf("expected an hour of Coding on the 1st and on the 2nd, got %+v", totals)
+	}
+}
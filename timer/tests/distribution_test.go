@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestComputeDistributionByHour(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	at := func(hour, min int) time.Time { return time.Date(2025, 9, 17, hour, min, 0, 0, time.Local) }
+	session := timer.Session{
+		Label: "Coding", Kind: timer.KindWork, Status: timer.StatusCompleted,
+		StartTime: at(9, 30), EndTime: at(11, 15),
+		Pauses: []timer.Pause{{StartTime: at(10, 0), EndTime: at(10, 15)}},
+	}
+	if err := storage.SaveTimerData(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	dist, err := storage.ComputeDistribution(timer.TimeFrame{}, timer.DistributionOptions{By: timer.ByHour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dist.Buckets) != 24 {
+		t.Fatalf("expected 24 hours, got %d", len(dist.Buckets))
+	}
+	for hour, want := range map[int]struct {
+		worked    time.Duration
+		pomodoros float64
+	}{
+		9:  {30 * time.Minute, 1.0 / 3},
+		10: {45 * time.Minute, 1.0 / 2},
+		11: {15 * time.Minute, 1.0 / 6},
+		12: {0, 0},
+	} {
+		b := dist.Buckets[hour]
+		if b.Worked != want.worked || math.Abs(b.Pomodoros-want.pomodoros) > 1e-9 {
+			t.Errorf("%s: expected %s and %.3f pomodoros, got %s and %.3f", b.Name, want.worked, want.pomodoros, b.Worked, b.Pomodoros)
+		}
+	}
+}
+
+func TestComputeDistributionByWeekday(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	// from Wednesday 23:00 to Thursday 01:00
+	start := time.Date(2025, 9, 17, 23, 0, 0, 0, time.Local)
+	session := timer.Session{Label: "Coding", Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: start, EndTime: start.Add(2 * time.Hour)}
+	if err := storage.SaveTimerData(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := timer.DistributionOptions{By: timer.ByWeekday, WeekStart: time.Monday}
+	dist, err := storage.ComputeDistribution(timer.TimeFrame{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dist.Buckets[0].Name != "Monday" {
+		t.Errorf("expected the week to start on Monday, got %s", dist.Buckets[0].Name)
+	}
+	if wed, thu := dist.Buckets[2], dist.Buckets[3]; wed.Worked != time.Hour || thu.Worked != time.Hour || wed.Pomodoros != 0.5 {
+		t.Errorf("expected the session to be split between Wednesday and Thursday, got %+v and %+v", wed, thu)
+	}
+
+	// with days starting at 4 the hour after midnight is still Wednesday
+	opts.Boundary.Hour = 4
+	dist, err = storage.ComputeDistribution(timer.TimeFrame{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wed := dist.Buckets[2]; wed.Worked != 2*time.Hour || wed.Pomodoros != 1 {
+		t.Errorf("expected the whole session on Wednesday, got %+v", wed)
+	}
+}
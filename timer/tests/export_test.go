@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestSessionJSON(t *testing.T) {
+	start := time.Date(2025, 9, 17, 14, 0, 0, 0, time.UTC)
+	session := timer.Session{
+		ID:              7,
+		Label:           "Coding",
+		Kind:            timer.KindWork,
+		Status:          timer.StatusCompleted,
+		PlannedDuration: 25 * time.Minute,
+		StartTime:       start,
+		EndTime:         start.Add(30 * time.Minute),
+		Pauses:          []timer.Pause{{StartTime: start.Add(10 * time.Minute), EndTime: start.Add(15 * time.Minute)}},
+		Tags:            []string{"client-a"},
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"id":               7.0,
+		"label":            "Coding",
+		"kind":             "work",
+		"status":           "completed",
+		"start_time":       "2025-09-17T14:00:00Z",
+		"end_time":         "2025-09-17T14:30:00Z",
+		"duration_seconds": 1800.0,
+		"net_seconds":      1500.0,
+		"paused_seconds":   300.0,
+		"planned_seconds":  1500.0,
+		"note":             "",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+	if _, ok := got["deleted_at"]; ok {
+		t.Error("deleted_at should be left out of sessions that are not deleted")
+	}
+	if pauses, ok := got["pauses"].([]any); !ok || len(pauses) != 1 {
+		t.Errorf("pauses = %v, want a list of one pause", got["pauses"])
+	}
+}
+
+func TestPomoStatsJSON(t *testing.T) {
+	stats := timer.PomoStats{
+		TimeFrame:         timer.TimeFrame{Name: "all"},
+		TotalSessions:     3,
+		TotalWorkDuration: 75 * time.Minute,
+		TimeSpentPerLabel: map[string]time.Duration{"Coding": 50 * time.Minute, "Reading": 25 * time.Minute},
+		PomosPerLabel:     map[string]int{"Coding": 2, "Reading": 1},
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		TimeFrame struct {
+			Name  string     `json:"name"`
+			Start *time.Time `json:"start"`
+		} `json:"timeframe"`
+		TotalSessions    int   `json:"total_sessions"`
+		TotalWorkSeconds int64 `json:"total_work_seconds"`
+		Labels           []struct {
+			Label       string `json:"label"`
+			Sessions    int    `json:"sessions"`
+			WorkSeconds int64  `json:"work_seconds"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.TimeFrame.Name != "all" || got.TimeFrame.Start != nil {
+		t.Errorf("unexpected timeframe %+v", got.TimeFrame)
+	}
+	if got.TotalSessions != 3 || got.TotalWorkSeconds != 4500 {
+		t.Errorf("unexpected totals in %s", data)
+	}
+	if len(got.Labels) != 2 || got.Labels[0].Label != "Coding" || got.Labels[0].Sessions != 2 || got.Labels[0].WorkSeconds != 3000 {
+		t.Errorf("expected the labels with the most work first, got %+v", got.Labels)
+	}
+}
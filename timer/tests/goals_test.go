@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestSetGoalReplacesSamePeriodAndLabel(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	first := timer.Goal{Period: timer.GoalDaily, Label: "Coding", Pomodoros: 6}
+	if err := storage.SetGoal(&first); err != nil {
+		t.Fatal(err)
+	}
+	second := timer.Goal{Period: timer.GoalDaily, Label: "Coding", Duration: 3 * time.Hour}
+	if err := storage.SetGoal(&second); err != nil {
+		t.Fatal(err)
+	}
+	weekly := timer.Goal{Period: timer.GoalWeekly, Duration: 20 * time.Hour}
+	if err := storage.SetGoal(&weekly); err != nil {
+		t.Fatal(err)
+	}
+
+	goals, err := storage.ListGoals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(goals) != 2 {
+		t.Fatalf("expected 2 goals, got %+v", goals)
+	}
+	if second.ID != first.ID || goals[0].Pomodoros != 0 || goals[0].Duration != 3*time.Hour {
+		t.Errorf("expected the daily Coding goal to be replaced, got %+v", goals[0])
+	}
+
+	if err := storage.SetGoal(&timer.Goal{Period: timer.GoalDaily, Pomodoros: 4, Duration: time.Hour}); err == nil {
+		t.Error("expected a goal with both targets to be rejected")
+	}
+	if err := storage.SetGoal(&timer.Goal{Period: "monthly", Pomodoros: 4}); err == nil {
+		t.Error("expected an unknown period to be rejected")
+	}
+}
+
+func TestRemoveGoal(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	goal := timer.Goal{Period: timer.GoalDaily, Pomodoros: 6}
+	if err := storage.SetGoal(&goal); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.RemoveGoal(goal.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.RemoveGoal(goal.ID); !errors.Is(err, timer.ErrGoalNotFound) {
+		t.Errorf("expected removing twice to fail with ErrGoalNotFound, got %v", err)
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	// Wednesday
+	now := time.Date(2025, 9, 17, 18, 0, 0, 0, time.Local)
+	at := func(day, hour int) time.Time { return time.Date(2025, 9, day, hour, 0, 0, 0, time.Local) }
+	sessions := []timer.Session{
+		{Label: "Coding", Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: at(17, 9), EndTime: at(17, 10)},
+		{Label: "Coding", Kind: timer.KindWork, Status: timer.StatusAborted, StartTime: at(17, 11), EndTime: at(17, 12)},
+		{Label: "Reading", Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: at(17, 13), EndTime: at(17, 14)},
+		{Label: "Coding", Kind: timer.KindShortBreak, Status: timer.StatusCompleted, StartTime: at(17, 14), EndTime: at(17, 15)},
+		// Monday, earlier this week
+		{Label: "Coding", Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: at(15, 9), EndTime: at(15, 10)},
+		// Sunday, last week
+		{Label: "Coding", Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: at(14, 9), EndTime: at(14, 10)},
+	}
+	for i := range sessions {
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, goal := range []timer.Goal{
+		{Period: timer.GoalDaily, Label: "Coding", Pomodoros: 2},
+		{Period: timer.GoalDaily, Duration: 3 * time.Hour},
+		{Period: timer.GoalWeekly, Label: "Coding", Duration: 4 * time.Hour},
+	} {
+		if err := storage.SetGoal(&goal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	progress, err := storage.GoalProgress(now, timer.DayBoundary{}, time.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 3 {
+		t.Fatalf("expected 3 goals, got %+v", progress)
+	}
+
+	// daily goals come first, the one over every label before Coding
+	all, coding, weekly := progress[0], progress[1], progress[2]
+	if all.Worked != 3*time.Hour || all.Pomodoros != 2 || !all.Done() {
+		t.Errorf("expected the daily goal over every label to be reached with 3h, got %+v", all)
+	}
+	if coding.Pomodoros != 1 || coding.Done() || coding.Fraction() != 0.5 {
+		t.Errorf("expected 1 of 2 Coding pomodoros today, got %+v", coding)
+	}
+	if weekly.Worked != 3*time.Hour || weekly.Done() || weekly.Fraction() != 0.75 {
+		t.Errorf("expected 3h of Coding this week, got %+v", weekly)
+	}
+
+	// at 2am on Thursday a night owl's day is still Wednesday, and with
+	// weeks from Sunday the session on the 14th is this week's
+	nightOwl := timer.DayBoundary{Hour: 4}
+	progress, err = storage.GoalProgress(at(18, 2), nightOwl, time.Sunday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	coding, weekly = progress[1], progress[2]
+	if coding.Pomodoros != 1 || !coding.TimeFrame.Start.Equal(at(17, 4)) {
+		t.Errorf("expected Wednesday from 4am with 1 Coding pomodoro, got %+v", coding)
+	}
+	if weekly.Worked != 4*time.Hour || !weekly.Done() || !weekly.TimeFrame.Start.Equal(at(14, 4)) {
+		t.Errorf("expected the week from Sunday 4am with 4h of Coding, got %+v", weekly)
+	}
+	progress, err = storage.GoalProgress(at(18, 2), timer.DayBoundary{}, time.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress[1].Pomodoros != 0 {
+		t.Errorf("expected no Coding pomodoros yet on Thursday from midnight, got %+v", progress[1])
+	}
+}
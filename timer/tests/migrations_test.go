@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+// newBaselineDB returns a database with the schema of the first release and
+// one session in it, from before migrations were tracked
+func newBaselineDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			label TEXT NOT NULL,
+			start_time INTEGER NOT NULL,
+			end_time INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO sessions (label, start_time, end_time) VALUES ('Old', 1700000000, 1700001500)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func migrateTo(t *testing.T, db *sql.DB, version int) {
+	t.Helper()
+	if _, err := timer.Migrate(db, version); err != nil {
+		t.Fatalf("migrating to version %d: %v", version, err)
+	}
+}
+
+func TestMigration1KeepsBaselineSessions(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 1)
+
+	var label string
+	if err := db.QueryRow(`SELECT label FROM sessions WHERE id = 1`).Scan(&label); err != nil {
+		t.Fatal(err)
+	}
+	if label != "Old" {
+		t.Errorf("expected the baseline session to survive, got label %q", label)
+	}
+}
+
+func TestMigration2AddsKind(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 2)
+
+	var kind string
+	if err := db.QueryRow(`SELECT kind FROM sessions WHERE id = 1`).Scan(&kind); err != nil {
+		t.Fatal(err)
+	}
+	if kind != string(timer.KindWork) {
+		t.Errorf("expected existing sessions to be work sessions, got %q", kind)
+	}
+}
+
+func TestMigration3AddsStatusAndPlanned(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 3)
+
+	var status string
+	var planned int
+	if err := db.QueryRow(`SELECT status, planned_seconds FROM sessions WHERE id = 1`).Scan(&status, &planned); err != nil {
+		t.Fatal(err)
+	}
+	if status != string(timer.StatusCompleted) || planned != 0 {
+		t.Errorf("expected existing sessions to be completed with no planned duration, got %q and %d", status, planned)
+	}
+}
+
+func TestMigration4CreatesPauses(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 4)
+
+	_, err := db.Exec(`INSERT INTO pauses (session_id, start_time, end_time) VALUES (1, 1700000100, 1700000200)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paused int
+	if err := db.QueryRow(`SELECT SUM(end_time - start_time) FROM pauses WHERE session_id = 1`).Scan(&paused); err != nil {
+		t.Fatal(err)
+	}
+	if paused != 100 {
+		t.Errorf("expected 100 seconds of pauses, got %d", paused)
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db := newBaselineDB(t)
+
+	applied, err := timer.Migrate(db, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != timer.LatestSchemaVersion() {
+		t.Errorf("expected %d migrations applied, got %d", timer.LatestSchemaVersion(), len(applied))
+	}
+
+	applied, err = timer.Migrate(db, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected nothing left to apply, got %d migrations", len(applied))
+	}
+}
+
+func TestMigrateToleratesUntrackedUpgrade(t *testing.T) {
+	db := newBaselineDB(t)
+	// the columns and table added on the fly by versions before migrations
+	for _, stmt := range []string{
+		`ALTER TABLE sessions ADD COLUMN kind TEXT NOT NULL DEFAULT 'work'`,
+		`ALTER TABLE sessions ADD COLUMN status TEXT NOT NULL DEFAULT 'completed'`,
+		`ALTER TABLE sessions ADD COLUMN planned_seconds INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TABLE pauses (id INTEGER PRIMARY KEY AUTOINCREMENT, session_id INTEGER NOT NULL, start_time INTEGER NOT NULL, end_time INTEGER NOT NULL)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := timer.Migrate(db, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigration5AddsNoteAndDeletedAt(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 5)
+
+	var note string
+	var deletedAt sql.NullInt64
+	if err := db.QueryRow(`SELECT note, deleted_at FROM sessions WHERE id = 1`).Scan(&note, &deletedAt); err != nil {
+		t.Fatal(err)
+	}
+	if note != "" || deletedAt.Valid {
+		t.Errorf("expected existing sessions to have no note and not be deleted, got %q and %v", note, deletedAt)
+	}
+}
+
+func TestMigration6CreatesSessionTags(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 6)
+
+	for _, tag := range []string{"client-a", "client-a", "writing"} {
+		if _, err := db.Exec(`INSERT OR IGNORE INTO session_tags (session_id, tag) VALUES (1, ?)`, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM session_tags WHERE session_id = 1`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected a tag to be attached to a session once, got %d tags", count)
+	}
+}
+
+func TestMigration7CreatesGoals(t *testing.T) {
+	db := newBaselineDB(t)
+	migrateTo(t, db, 7)
+
+	if _, err := db.Exec(`INSERT INTO goals (period, label, target_pomodoros) VALUES ('daily', '', 6)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO goals (period, label, target_pomodoros) VALUES ('daily', '', 8)`); err == nil {
+		t.Error("expected a second daily goal over every label to be rejected")
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+// newQueryTestStorage holds five sessions an hour apart, oldest first:
+// Coding 25m, coding 10m aborted, Code review 50m tagged work and review,
+// Reading 30m tagged book, and a 5m short break
+func newQueryTestStorage(t *testing.T) (*timer.SQLiteStorage, time.Time) {
+	t.Helper()
+	storage := newTestSQLiteStorage(t)
+	t.Cleanup(func() { storage.Close() })
+
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	sessions := []timer.Session{
+		{Label: "Coding", Kind: timer.KindWork, Status: timer.StatusCompleted, Tags: []string{"work"}},
+		{Label: "coding", Kind: timer.KindWork, Status: timer.StatusAborted},
+		{Label: "Code review", Kind: timer.KindWork, Status: timer.StatusCompleted, Tags: []string{"work", "review"}},
+		{Label: "Reading", Kind: timer.KindWork, Status: timer.StatusCompleted, Tags: []string{"book"}},
+		{Label: "Coding", Kind: timer.KindShortBreak, Status: timer.StatusCompleted},
+	}
+	durations := []time.Duration{25 * time.Minute, 10 * time.Minute, 50 * time.Minute, 30 * time.Minute, 5 * time.Minute}
+	for i := range sessions {
+		sessions[i].StartTime = start.Add(time.Duration(i) * time.Hour)
+		sessions[i].EndTime = sessions[i].StartTime.Add(durations[i])
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return storage, start
+}
+
+func labelsOf(sessions []timer.Session) []string {
+	labels := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		labels = append(labels, s.Label)
+	}
+	return labels
+}
+
+func TestQuerySessions(t *testing.T) {
+	storage, start := newQueryTestStorage(t)
+
+	cases := []struct {
+		name  string
+		query timer.SessionQuery
+		want  []string
+	}{
+		{"default is newest first", timer.SessionQuery{}, []string{"Coding", "Reading", "Code review", "coding", "Coding"}},
+		{"exact label", timer.SessionQuery{Label: "Coding", Kinds: []timer.SessionKind{timer.KindWork}}, []string{"Coding"}},
+		{"glob", timer.SessionQuery{Label: "Cod*", LabelMatch: timer.LabelGlob, Ascending: true}, []string{"Coding", "Code review", "Coding"}},
+		{"regex", timer.SessionQuery{Label: "(?i)^cod", LabelMatch: timer.LabelRegex, Kinds: []timer.SessionKind{timer.KindWork}, Ascending: true}, []string{"Coding", "coding", "Code review"}},
+		{"since and until", timer.SessionQuery{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour), Ascending: true}, []string{"coding", "Code review"}},
+		{"duration range", timer.SessionQuery{MinDuration: 20 * time.Minute, MaxDuration: 30 * time.Minute, Ascending: true}, []string{"Coding", "Reading"}},
+		{"status", timer.SessionQuery{Statuses: []timer.SessionStatus{timer.StatusAborted}}, []string{"coding"}},
+		{"every tag", timer.SessionQuery{Tags: []string{"work", "review"}}, []string{"Code review"}},
+		{"sort by duration", timer.SessionQuery{OrderBy: timer.OrderDuration, Limit: 2}, []string{"Code review", "Reading"}},
+		{"offset", timer.SessionQuery{Ascending: true, Limit: 2, Offset: 2}, []string{"Code review", "Reading"}},
+		{"offset without limit", timer.SessionQuery{Ascending: true, Offset: 3}, []string{"Reading", "Coding"}},
+	}
+	for _, c := range cases {
+		sessions, err := storage.QuerySessions(c.query)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		got := labelsOf(sessions)
+		if len(got) != len(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestQuerySessionsLoadsTags(t *testing.T) {
+	storage, _ := newQueryTestStorage(t)
+
+	sessions, err := storage.QuerySessions(timer.SessionQuery{Label: "Code review"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || len(sessions[0].Tags) != 2 || sessions[0].Tags[0] != "review" || sessions[0].Tags[1] != "work" {
+		t.Errorf("expected the tags review and work, got %+v", sessions)
+	}
+}
+
+func TestQuerySessionsRejectsBadRegex(t *testing.T) {
+	storage, _ := newQueryTestStorage(t)
+
+	if _, err := storage.QuerySessions(timer.SessionQuery{Label: "(", LabelMatch: timer.LabelRegex}); err == nil {
+		t.Error("expected an invalid regular expression to fail")
+	}
+}
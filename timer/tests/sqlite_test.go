@@ -1,7 +1,11 @@
 package tests
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -39,7 +43,7 @@ func TestSaveTimerData(t *testing.T) {
 	startTime := time.Date(2025, 9, 17, 12, 0, 0, 0, time.Local)
 	endTime := time.Date(2025, 9, 17, 12, 15, 0, 0, time.Local)
 
-	err := storage.SaveTimerData("Test", startTime, endTime)
+	err := storage.SaveTimerData(&timer.Session{Label: "Test", Kind: timer.KindWork, StartTime: startTime, EndTime: endTime})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -51,7 +55,7 @@ func TestListSessions(t *testing.T) {
 	startTime := time.Date(2025, 9, 17, 12, 0, 0, 0, time.Local)
 	endTime := time.Date(2025, 9, 17, 12, 15, 0, 0, time.Local)
 
-	err := storage.SaveTimerData("Test", startTime, endTime)
+	err := storage.SaveTimerData(&timer.Session{Label: "Test", Kind: timer.KindWork, StartTime: startTime, EndTime: endTime})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -64,3 +68,590 @@ func TestListSessions(t *testing.T) {
 		t.Fatalf("Expected 1 session, got %d", len(sessions))
 	}
 }
+
+func TestComputePomoStatsSeparatesBreaks(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Now().Add(-time.Hour)
+	sessions := []timer.Session{
+		{Label: "Test", Kind: timer.KindWork, StartTime: start, EndTime: start.Add(25 * time.Minute)},
+		{Label: "Test", Kind: timer.KindShortBreak, StartTime: start.Add(25 * time.Minute), EndTime: start.Add(30 * time.Minute)},
+		{Label: "Test", Kind: timer.KindLongBreak, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(45 * time.Minute)},
+	}
+	for i := range sessions {
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := storage.ComputePomoStats(timer.TimeFrame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalSessions != 1 {
+		t.Fatalf("Expected 1 work session, got %d", stats.TotalSessions)
+	}
+	if stats.TotalWorkDuration != 25*time.Minute {
+		t.Fatalf("Expected 25m of work, got %s", stats.TotalWorkDuration)
+	}
+	if stats.TotalBreakDuration != 20*time.Minute {
+		t.Fatalf("Expected 20m of breaks, got %s", stats.TotalBreakDuration)
+	}
+}
+
+func TestComputePomoStatsCompletion(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Now().Add(-2 * time.Hour)
+	sessions := []timer.Session{
+		{Label: "Test", Kind: timer.KindWork, Status: timer.StatusCompleted, PlannedDuration: 20 * time.Minute,
+			StartTime: start, EndTime: start.Add(20 * time.Minute)},
+		{Label: "Test", Kind: timer.KindWork, Status: timer.StatusAborted, PlannedDuration: 20 * time.Minute,
+			StartTime: start.Add(time.Hour), EndTime: start.Add(time.Hour + 5*time.Minute)},
+	}
+	for i := range sessions {
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := storage.ComputePomoStats(timer.TimeFrame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CompletionRate != 0.5 {
+		t.Fatalf("Expected a completion rate of 0.5, got %f", stats.CompletionRate)
+	}
+	if achieved := stats.PlannedAchievedPerLabel["Test"]; achieved != 0.625 {
+		t.Fatalf("Expected 62.5%% of the planned duration achieved, got %f", achieved)
+	}
+}
+
+func TestComputePomoStatsSessionLengths(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	for i, minutes := range []int{30, 10, 50, 20, 40} {
+		label := "Coding"
+		if minutes == 50 {
+			label = "Reading"
+		}
+		begin := start.Add(time.Duration(i) * time.Hour)
+		s := timer.Session{Label: label, Kind: timer.KindWork, StartTime: begin, EndTime: begin.Add(time.Duration(minutes) * time.Minute)}
+		if err := storage.SaveTimerData(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := storage.ComputePomoStats(timer.TimeFrame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ShortestSession != 10*time.Minute || stats.LongestSession != 50*time.Minute || stats.AverageSessionDuration != 30*time.Minute {
+		t.Errorf("expected sessions from 10m to 50m averaging 30m, got %s, %s and %s", stats.ShortestSession, stats.LongestSession, stats.AverageSessionDuration)
+	}
+	if stats.LongestSessionLabel != "Reading" {
+		t.Errorf("expected the longest session to be Reading, got %q", stats.LongestSessionLabel)
+	}
+	if stats.MedianSession != 30*time.Minute {
+		t.Errorf("expected a median of 30m, got %s", stats.MedianSession)
+	}
+	for pct, want := range map[int]time.Duration{25: 20 * time.Minute, 75: 40 * time.Minute, 90: 46 * time.Minute} {
+		if got := stats.SessionPercentiles[pct]; got != want {
+			t.Errorf("expected a %dth percentile of %s, got %s", pct, want, got)
+		}
+	}
+}
+
+func TestComputePomoStatsClipsSessionsToTimeFrame(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2025, month, day, hour, min, 0, 0, time.Local)
+	}
+	sessions := []timer.Session{
+		// 30 minutes in September, the pause is in August
+		{Label: "Work", Kind: timer.KindWork, StartTime: at(8, 31, 23, 30), EndTime: at(9, 1, 0, 30),
+			Pauses: []timer.Pause{{StartTime: at(8, 31, 23, 40), EndTime: at(8, 31, 23, 50)}}},
+		// 15 minutes in September, the pause is in October
+		{Label: "Work", Kind: timer.KindWork, StartTime: at(9, 30, 23, 45), EndTime: at(10, 1, 0, 15),
+			Pauses: []timer.Pause{{StartTime: at(10, 1, 0, 0), EndTime: at(10, 1, 0, 10)}}},
+		{Label: "Work", Kind: timer.KindShortBreak, StartTime: at(9, 30, 23, 55), EndTime: at(10, 1, 0, 5)},
+	}
+	for i := range sessions {
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tf, err := timer.ParseTimeFrame("2025-09", time.Now(), timer.DayBoundary{}, time.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := storage.ComputePomoStats(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalWorkDuration != 45*time.Minute || stats.TimeSpentPerLabel["Work"] != 45*time.Minute {
+		t.Errorf("expected 45m of work inside September, got %s", stats.TotalWorkDuration)
+	}
+	if stats.TotalBreakDuration != 5*time.Minute {
+		t.Errorf("expected 5m of break inside September, got %s", stats.TotalBreakDuration)
+	}
+	// the session started in August belongs to August
+	if stats.TotalSessions != 1 || stats.LongestSession != 20*time.Minute {
+		t.Errorf("expected the one session started in September at its full length, got %d and %s", stats.TotalSessions, stats.LongestSession)
+	}
+}
+
+func TestComputePomoStatsReturnsErrors(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	storage.Close()
+
+	if _, err := storage.ComputePomoStats(timer.TimeFrame{}); err == nil {
+		t.Error("expected an error from a closed database")
+	}
+}
+
+func TestPausesExcludedFromWorkedTime(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Now().Add(-time.Hour)
+	session := timer.Session{
+		Label:     "Test",
+		Kind:      timer.KindWork,
+		StartTime: start,
+		EndTime:   start.Add(30 * time.Minute),
+		Pauses: []timer.Pause{
+			{StartTime: start.Add(5 * time.Minute), EndTime: start.Add(8 * time.Minute)},
+			{StartTime: start.Add(20 * time.Minute), EndTime: start.Add(22 * time.Minute)},
+		},
+	}
+	if err := storage.SaveTimerData(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || len(sessions[0].Pauses) != 2 {
+		t.Fatalf("Expected 1 session with 2 pauses, got %+v", sessions)
+	}
+	if net := sessions[0].NetDuration(); net != 25*time.Minute {
+		t.Fatalf("Expected 25m net duration, got %s", net)
+	}
+
+	stats, err := storage.ComputePomoStats(timer.TimeFrame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalWorkDuration != 25*time.Minute {
+		t.Fatalf("Expected 25m of work, got %s", stats.TotalWorkDuration)
+	}
+}
+
+func TestMergeFromSkipsDuplicates(t *testing.T) {
+	dst := newTestSQLiteStorage(t)
+	defer dst.Close()
+	src := newTestSQLiteStorage(t)
+	defer src.Close()
+
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	shared := timer.Session{Label: "Coding", Kind: timer.KindWork, StartTime: start, EndTime: start.Add(25 * time.Minute)}
+	only := timer.Session{
+		Label:     "Reading",
+		Kind:      timer.KindWork,
+		Status:    timer.StatusAborted,
+		StartTime: start.Add(time.Hour),
+		EndTime:   start.Add(time.Hour + 20*time.Minute),
+		Pauses:    []timer.Pause{{StartTime: start.Add(time.Hour + 5*time.Minute), EndTime: start.Add(time.Hour + 8*time.Minute)}},
+	}
+
+	existing := shared
+	if err := dst.SaveTimerData(&existing); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []timer.Session{shared, only} {
+		if err := src.SaveTimerData(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	merged, skipped, err := dst.MergeFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != 1 || skipped != 1 {
+		t.Fatalf("expected 1 merged and 1 skipped, got %d and %d", merged, skipped)
+	}
+
+	sessions, err := dst.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	got := sessions[0]
+	if got.Label != "Reading" || got.Status != timer.StatusAborted || len(got.Pauses) != 1 {
+		t.Errorf("merged session not copied faithfully: %+v", got)
+	}
+
+	// merging again changes nothing
+	merged, skipped, err = dst.MergeFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != 0 || skipped != 2 {
+		t.Errorf("expected a second merge to skip everything, got %d merged and %d skipped", merged, skipped)
+	}
+}
+
+func TestMergeFromKeepsDeletedSessions(t *testing.T) {
+	dst := newTestSQLiteStorage(t)
+	defer dst.Close()
+	src := newTestSQLiteStorage(t)
+	defer src.Close()
+
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	session := timer.Session{Label: "Coding", Kind: timer.KindWork, StartTime: start, EndTime: start.Add(25 * time.Minute)}
+	if err := src.SaveTimerData(&session); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.DeleteSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	want, err := src.GetSession(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, skipped, err := dst.MergeFrom(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != 1 || skipped != 0 {
+		t.Fatalf("expected the deleted session to be merged, got %d merged and %d skipped", merged, skipped)
+	}
+	deleted, err := dst.QuerySessions(timer.SessionQuery{Deleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || !deleted[0].DeletedAt.Equal(want.DeletedAt) {
+		t.Fatalf("expected the session to stay deleted at %s, got %+v", want.DeletedAt, deleted)
+	}
+	if err := dst.RestoreSession(deleted[0].ID); err != nil {
+		t.Errorf("expected the merged session to be restorable: %v", err)
+	}
+
+	if merged, skipped, err = dst.MergeFrom(src); err != nil || merged != 0 || skipped != 1 {
+		t.Errorf("expected a second merge to skip the deleted session, got %d merged, %d skipped and %v", merged, skipped, err)
+	}
+}
+
+func TestMergeFromLeavesSourceAsItIs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pomodoro.db")
+	src, err := timer.NewSQLiteStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	if err := src.SaveTimerData(&timer.Session{Label: "Coding", Kind: timer.KindWork, StartTime: start, EndTime: start.Add(25 * time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	src.Close()
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err = timer.NewReadOnlySQLiteStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := newTestSQLiteStorage(t)
+	defer dst.Close()
+	merged, _, err := dst.MergeFrom(src)
+	src.Close()
+	if err != nil || merged != 1 {
+		t.Fatalf("expected 1 session to be merged, got %d and %v", merged, err)
+	}
+	if after, err := os.ReadFile(path); err != nil || !bytes.Equal(before, after) {
+		t.Errorf("expected merging to leave the source file as it was: %v", err)
+	}
+
+	// a database of an older version is refused rather than migrated
+	old := filepath.Join(dir, "old.db")
+	db, err := sql.Open("sqlite3", old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := timer.Migrate(db, 4); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if _, err := timer.NewReadOnlySQLiteStorage(old); !errors.Is(err, timer.ErrSchemaOutdated) {
+		t.Fatalf("expected ErrSchemaOutdated, got %v", err)
+	}
+	migrations, err := timer.ReadMigrationStatus(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := migrations[len(migrations)-1]; last.Applied() {
+		t.Error("expected the older database not to be migrated")
+	}
+}
+
+func TestOverlappingSessions(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Date(2025, 9, 17, 14, 0, 0, 0, time.Local)
+	meeting := timer.Session{Label: "Meeting", Kind: timer.KindWork, StartTime: start, EndTime: start.Add(90 * time.Minute)}
+	if err := storage.SaveTimerData(&meeting); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		start, end time.Time
+		want       int
+	}{
+		{"inside", start.Add(30 * time.Minute), start.Add(time.Hour), 1},
+		{"straddling the start", start.Add(-time.Hour), start.Add(time.Minute), 1},
+		{"covering", start.Add(-time.Hour), start.Add(3 * time.Hour), 1},
+		{"right before", start.Add(-time.Hour), start, 0},
+		{"right after", start.Add(90 * time.Minute), start.Add(2 * time.Hour), 0},
+	}
+	for _, c := range cases {
+		sessions, err := storage.OverlappingSessions(c.start, c.end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) != c.want {
+			t.Errorf("%s: expected %d overlapping sessions, got %d", c.name, c.want, len(sessions))
+		}
+	}
+}
+
+func TestUpdateSessionClipsPauses(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Date(2025, 9, 17, 14, 0, 0, 0, time.Local)
+	session := timer.Session{
+		Label:     "Wrting",
+		Kind:      timer.KindWork,
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Pauses: []timer.Pause{
+			{StartTime: start.Add(10 * time.Minute), EndTime: start.Add(15 * time.Minute)},
+			{StartTime: start.Add(40 * time.Minute), EndTime: start.Add(50 * time.Minute)},
+		},
+	}
+	if err := storage.SaveTimerData(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	session.Label = "Writing"
+	session.Note = "forgot to quit"
+	session.EndTime = start.Add(45 * time.Minute)
+	if err := storage.UpdateSession(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storage.GetSession(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Label != "Writing" || got.Note != "forgot to quit" || !got.EndTime.Equal(session.EndTime) {
+		t.Errorf("edit not saved: %+v", got)
+	}
+	if got.PausedDuration() != 10*time.Minute {
+		t.Errorf("expected the second pause to be cut to 5 minutes, got %s paused in total", got.PausedDuration())
+	}
+}
+
+func TestDeleteAndRestoreSession(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	now := time.Now()
+	session := timer.Session{Label: "Mistake", Kind: timer.KindWork, StartTime: now.Add(-30 * time.Minute), EndTime: now.Add(-5 * time.Minute)}
+	if err := storage.SaveTimerData(&session); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("expected the deleted session to be hidden, got %d sessions", len(sessions))
+	}
+	stats, err := storage.ComputePomoStats(timer.TimeFrame{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalSessions != 0 || stats.TotalWorkDuration != 0 {
+		t.Errorf("expected the deleted session to be left out of the stats, got %+v", stats)
+	}
+	if err := storage.DeleteSession(session.ID); !errors.Is(err, timer.ErrSessionNotFound) {
+		t.Errorf("expected deleting twice to fail with ErrSessionNotFound, got %v", err)
+	}
+	deleted, err := storage.QuerySessions(timer.SessionQuery{Deleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || !deleted[0].Deleted() {
+		t.Errorf("expected one deleted session, got %+v", deleted)
+	}
+
+	if err := storage.RestoreSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err = storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("expected the restored session to be listed, got %d sessions", len(sessions))
+	}
+	if err := storage.RestoreSession(session.ID); !errors.Is(err, timer.ErrSessionNotFound) {
+		t.Errorf("expected restoring a session that is not deleted to fail, got %v", err)
+	}
+}
+
+func TestRelabelSessions(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	for i, label := range []string{"coding", "Coding", "code", "code", "Reading"} {
+		s := timer.Session{Label: label, Kind: timer.KindWork, StartTime: start.Add(time.Duration(i) * time.Hour), EndTime: start.Add(time.Duration(i)*time.Hour + 25*time.Minute)}
+		if err := storage.SaveTimerData(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := storage.RelabelSessions([]string{"coding", "code"}, "Coding", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["coding"] != 1 || counts["code"] != 2 {
+		t.Errorf("unexpected dry-run counts: %v", counts)
+	}
+	labels, err := storage.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 4 {
+		t.Fatalf("expected a dry run to change nothing, got labels %+v", labels)
+	}
+
+	if _, err := storage.RelabelSessions([]string{"coding", "code"}, "Coding", false); err != nil {
+		t.Fatal(err)
+	}
+	labels, err = storage.ListLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 2 || labels[0].Label != "Coding" || labels[0].Sessions != 4 || labels[0].WorkDuration != 100*time.Minute {
+		t.Errorf("expected Coding to hold the 4 merged sessions, got %+v", labels)
+	}
+}
+
+func TestRelabelSessionsWithDuplicateLabel(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	for i, label := range []string{"a", "a", "b"} {
+		s := timer.Session{Label: label, Kind: timer.KindWork, StartTime: start.Add(time.Duration(i) * time.Hour), EndTime: start.Add(time.Duration(i)*time.Hour + 25*time.Minute)}
+		if err := storage.SaveTimerData(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// pomo label merge a a --into b
+	counts, err := storage.RelabelSessions([]string{"a", "a"}, "b", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts["a"] != 2 {
+		t.Errorf("expected the 2 sessions of a to be counted once, got %v", counts)
+	}
+}
+
+func TestRelabelSessionsMovesGoals(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	now := time.Date(2025, 9, 17, 18, 0, 0, 0, time.Local)
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	for i, label := range []string{"code", "code", "coding"} {
+		s := timer.Session{Label: label, Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: start.Add(time.Duration(i) * time.Hour), EndTime: start.Add(time.Duration(i)*time.Hour + 25*time.Minute)}
+		if err := storage.SaveTimerData(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, goal := range []timer.Goal{
+		{Period: timer.GoalDaily, Label: "code", Pomodoros: 3},
+		{Period: timer.GoalDaily, Label: "coding", Pomodoros: 4},
+		{Period: timer.GoalWeekly, Label: "coding", Duration: 10 * time.Hour},
+		{Period: timer.GoalDaily, Label: "", Pomodoros: 6},
+	} {
+		if err := storage.SetGoal(&goal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a renamed label keeps its goal and the progress toward it
+	if _, err := storage.RelabelSessions([]string{"code"}, "Code", false); err != nil {
+		t.Fatal(err)
+	}
+	progress, err := storage.GoalProgress(now, timer.DayBoundary{}, time.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, p := range progress {
+		if p.Goal.Label == "Code" {
+			found = true
+			if p.Pomodoros != 2 {
+				t.Errorf("expected the renamed goal to count 2 pomodoros, got %+v", p)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected the goal to move to Code, got %+v", progress)
+	}
+
+	// on a merge the goal of the label listed first wins
+	if _, err := storage.RelabelSessions([]string{"Code", "coding"}, "Coding", false); err != nil {
+		t.Fatal(err)
+	}
+	goals, err := storage.ListGoals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"daily/": "6 pomodoros", "daily/Coding": "3 pomodoros", "weekly/Coding": "10h0m0s"}
+	if len(goals) != len(want) {
+		t.Fatalf("expected %d goals after the merge, got %+v", len(want), goals)
+	}
+	for _, goal := range goals {
+		if target := want[string(goal.Period)+"/"+goal.Label]; target != goal.Target() {
+			t.Errorf("expected %s %q to be %q, got %q", goal.Period, goal.Label, target, goal.Target())
+		}
+	}
+}
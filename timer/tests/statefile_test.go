@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestCorruptStateFileIsMovedAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// what a crash in the middle of a write can leave behind
+	if err := os.WriteFile(path, []byte(`{"running":true,"label":"Cod`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := timer.ReadStateFile(path); !errors.Is(err, timer.ErrCorruptStateFile) {
+		t.Fatalf("expected ErrCorruptStateFile, got %v", err)
+	}
+	moved, err := timer.MoveCorruptStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != path+".corrupt" {
+		t.Errorf("expected the file to move to %s.corrupt, got %s", path, moved)
+	}
+	if _, err := os.Stat(moved); err != nil {
+		t.Errorf("expected the corrupt file to be kept: %v", err)
+	}
+
+	state, err := timer.ReadStateFile(path)
+	if err != nil || state.Running {
+		t.Fatalf("expected an idle timer once the corrupt file is gone, got %+v and %v", state, err)
+	}
+	if err := timer.WriteStateFile(path, timer.State{Running: true, Label: "Coding"}); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := timer.ReadStateFile(path); err != nil || state.Label != "Coding" {
+		t.Errorf("expected a new state file to be written and read back, got %+v and %v", state, err)
+	}
+}
+
+// the PID of a process that has exited
+func deadPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run a process to get a dead PID: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestCrashAfterCompletedInterval(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+	pt.Subscribe(timer.NewStateFile(path, pt))
+	pt.Subscribe(timer.StorageObserver{Storage: storage})
+
+	// the state file as the process leaves it if it dies right after the
+	// work interval is saved
+	checkpoints := make(chan timer.State, 1)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Type == timer.IntervalCompleted {
+			state, err := timer.ReadStateFile(path)
+			if err != nil {
+				t.Error(err)
+			}
+			checkpoints <- state
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		done <- pt.Start()
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(25 * time.Minute)
+	checkpoint := <-checkpoints
+	pt.ControlChan <- "quit"
+	<-done
+
+	checkpoint.PID = deadPID(t)
+	if checkpoint.Running || checkpoint.Interrupted() {
+		t.Errorf("expected no interrupted interval after a completed one, got %+v", checkpoint)
+	}
+	// a checkpoint written while the interval still counted as running
+	checkpoint.Running = true
+	if checkpoint.Interrupted() {
+		t.Error("expected an interval with nothing left not to be interrupted")
+	}
+
+	sessions, err := storage.ListSessions(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := 0
+	for _, session := range sessions {
+		if session.Kind == timer.KindWork {
+			work++
+			if session.Status != timer.StatusCompleted {
+				t.Errorf("expected the work interval to be saved as completed, got %s", session.Status)
+			}
+		}
+	}
+	if work != 1 {
+		t.Errorf("expected the work interval to be saved once, got %d", work)
+	}
+}
+
+func TestStateOfReusedPID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+	pt.Subscribe(timer.NewStateFile(path, pt))
+
+	done := make(chan bool)
+	go func() {
+		done <- pt.Start()
+	}()
+	clock.BlockUntil(1)
+	pt.ControlChan <- "quit"
+	<-done
+
+	state, err := timer.ReadStateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.PID != os.Getpid() || !state.Alive() {
+		t.Fatalf("expected the state of this process to be alive, got %+v", state)
+	}
+	if runtime.GOOS != "linux" {
+		t.Skip("process start times are only known on Linux")
+	}
+	if state.PIDStart == "" {
+		t.Fatal("expected the start of this process to be recorded")
+	}
+	// what a state file from before a reboot looks like once its pid is
+	// taken by another process
+	state.PIDStart = "another-boot/1"
+	if state.Alive() {
+		t.Error("expected a process that reused the pid not to count as the timer")
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+// the state of a timer 10 minutes into a work interval
+func runningState() timer.State {
+	return timer.State{
+		Running:          true,
+		Label:            "Coding",
+		Phase:            timer.KindWork,
+		Description:      "Coding [#1]",
+		Round:            1,
+		Position:         "[#1]",
+		RemainingSeconds: 15 * 60,
+		PlannedSeconds:   25 * 60,
+	}
+}
+
+func writeStatus(t *testing.T, format string, state timer.State, goals []timer.GoalProgress) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := timer.WriteStatus(&out, format, state, goals); err != nil {
+		t.Fatalf("format %q: %v", format, err)
+	}
+	return out.String()
+}
+
+func TestStatusJSON(t *testing.T) {
+	goals := []timer.GoalProgress{{Goal: timer.Goal{Period: timer.GoalDaily, Pomodoros: 6}, Pomodoros: 4}}
+	var status struct {
+		Running          bool            `json:"running"`
+		Label            string          `json:"label"`
+		RemainingSeconds int64           `json:"remaining_seconds"`
+		Goals            json.RawMessage `json:"goals"`
+	}
+	if err := json.Unmarshal([]byte(writeStatus(t, "json", runningState(), goals)), &status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Running || status.Label != "Coding" || status.RemainingSeconds != 900 {
+		t.Errorf("expected the running timer, got %+v", status)
+	}
+	var decoded []timer.GoalProgress
+	if err := json.Unmarshal(status.Goals, &decoded); err != nil || len(decoded) != 1 || decoded[0].Pomodoros != 4 {
+		t.Errorf("expected the goal, got %s", status.Goals)
+	}
+
+	idle := writeStatus(t, "json", timer.State{}, nil)
+	if !strings.Contains(idle, `"running":false`) || !strings.Contains(idle, `"goals":[]`) {
+		t.Errorf("expected an idle timer with an empty list of goals, got %s", idle)
+	}
+}
+
+func TestStatusWaybar(t *testing.T) {
+	paused := runningState()
+	paused.Paused = true
+	onBreak := runningState()
+	onBreak.Phase = timer.KindShortBreak
+	onBreak.Description = "Short break"
+	onBreak.RemainingSeconds = 60
+	onBreak.PlannedSeconds = 5 * 60
+
+	tests := []struct {
+		name       string
+		state      timer.State
+		text       string
+		class      string
+		percentage int
+	}{
+		{"running", runningState(), "🍅 15:00", "work", 40},
+		{"paused", paused, "⏸ 15:00", "paused", 40},
+		{"break", onBreak, "☕ 01:00", "short_break", 80},
+		{"idle", timer.State{}, "", "idle", 0},
+	}
+	for _, tt := range tests {
+		var module struct {
+			Text       string `json:"text"`
+			Tooltip    string `json:"tooltip"`
+			Class      string `json:"class"`
+			Percentage int    `json:"percentage"`
+		}
+		if err := json.Unmarshal([]byte(writeStatus(t, "waybar", tt.state, nil)), &module); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if module.Text != tt.text || module.Class != tt.class || module.Percentage != tt.percentage {
+			t.Errorf("%s: expected %q, class %q and %d%%, got %+v", tt.name, tt.text, tt.class, tt.percentage, module)
+		}
+	}
+
+	goals := []timer.GoalProgress{{Goal: timer.Goal{Period: timer.GoalWeekly, Label: "Coding", Pomodoros: 20}, Pomodoros: 3}}
+	var module struct {
+		Tooltip string `json:"tooltip"`
+	}
+	if err := json.Unmarshal([]byte(writeStatus(t, "waybar", runningState(), goals)), &module); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Coding [#1] (work)\n🎯 Weekly Coding: 3/20 pomodoros"; module.Tooltip != want {
+		t.Errorf("expected tooltip %q, got %q", want, module.Tooltip)
+	}
+}
+
+func TestStatusTmux(t *testing.T) {
+	paused := runningState()
+	paused.Paused = true
+	onBreak := runningState()
+	onBreak.Phase = timer.KindLongBreak
+
+	tests := []struct {
+		name  string
+		state timer.State
+		want  string
+	}{
+		{"running", runningState(), "#[fg=red]🍅 Coding 15:00#[default]\n"},
+		{"paused", paused, "#[fg=yellow]⏸ 15:00#[default]\n"},
+		{"break", onBreak, "#[fg=green]☕ 15:00#[default]\n"},
+		{"idle", timer.State{}, "\n"},
+	}
+	for _, tt := range tests {
+		if got := writeStatus(t, "tmux", tt.state, nil); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestStatusTemplate(t *testing.T) {
+	goals := []timer.GoalProgress{{Goal: timer.Goal{Period: timer.GoalDaily, Pomodoros: 6}, Pomodoros: 6}}
+	format := "{{.Label}} {{.Remaining}} {{.Percent}}%{{range .Goals}} {{.Goal.Name}} {{.Progress}}{{end}}"
+	if got, want := writeStatus(t, format, runningState(), goals), "Coding 15:00 40% Daily 6/6 pomodoros\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := writeStatus(t, "{{if .Running}}busy{{else}}idle{{end}}", timer.State{}, nil); got != "idle\n" {
+		t.Errorf("expected an idle timer, got %q", got)
+	}
+
+	var out bytes.Buffer
+	if err := timer.WriteStatus(&out, "{{.Label", runningState(), nil); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("expected an invalid format error, got %v", err)
+	}
+	if err := timer.WriteStatus(&out, "{{.Missing}}", runningState(), nil); err == nil {
+		t.Error("expected an error for a field the view does not have")
+	}
+}
+
+func TestLiveStateOfDeadTimer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// the checkpoint of a timer whose process was killed
+	checkpoint := runningState()
+	checkpoint.PID = deadPID(t)
+	if err := timer.WriteStateFile(path, checkpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := timer.ReadLiveState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Running {
+		t.Fatalf("expected a dead timer not to be running, got %+v", state)
+	}
+	if got := writeStatus(t, "tmux", state, nil); got != "\n" {
+		t.Errorf("expected an empty tmux status, got %q", got)
+	}
+	if got := writeStatus(t, "{{.Running}}", state, nil); got != "false\n" {
+		t.Errorf("expected the template to see no running timer, got %q", got)
+	}
+}
+
+func TestLiveStateOfCorruptOrMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte(`{"running":true,"label":"Cod`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := timer.ReadLiveState(path); err != nil || state.Running {
+		t.Errorf("expected a corrupt state file to read as idle, got %+v and %v", state, err)
+	}
+	if state, err := timer.ReadLiveState(filepath.Join(dir, "missing.json")); err != nil || state.Running {
+		t.Errorf("expected a missing state file to read as idle, got %+v and %v", state, err)
+	}
+}
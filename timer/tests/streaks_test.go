@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestDayBoundary(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	boundary := timer.DayBoundary{Location: berlin, Hour: 4}
+
+	// 1:30 in Berlin is still the evening before with days starting at 4
+	late := time.Date(2025, 9, 17, 23, 30, 0, 0, time.UTC)
+	if got, want := boundary.Day(late), time.Date(2025, 9, 17, 0, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	early := time.Date(2025, 9, 18, 2, 30, 0, 0, time.UTC)
+	if got, want := boundary.Day(early), time.Date(2025, 9, 18, 0, 0, 0, 0, berlin); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestComputeStreaks(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	day := func(d, hour int) time.Time { return time.Date(2025, 9, d, hour, 0, 0, 0, time.Local) }
+	work := func(label string, start time.Time) timer.Session {
+		return timer.Session{Label: label, Kind: timer.KindWork, Status: timer.StatusCompleted, StartTime: start, EndTime: start.Add(25 * time.Minute)}
+	}
+	sessions := []timer.Session{
+		// a three day Coding streak, broken on the 4th
+		work("Coding", day(1, 9)), work("Coding", day(2, 9)), work("Coding", day(3, 9)),
+		// Reading from the 5th, the 6th past midnight only with day_start
+		work("Reading", day(5, 20)), work("Reading", day(7, 1)),
+		work("Reading", day(7, 20)), work("Reading", day(8, 20)),
+		work("Coding", day(8, 9)),
+	}
+	for i := range sessions {
+		if err := storage.SaveTimerData(&sessions[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := day(9, 12)
+
+	streaks, err := storage.ComputeStreaks(timer.StreakOptions{MinPomodoros: 1}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLabel := make(map[string]timer.Streak)
+	for _, s := range streaks {
+		byLabel[s.Label] = s
+	}
+	if streaks[0].Label != "" {
+		t.Errorf("expected the streak over every label first, got %q", streaks[0].Label)
+	}
+	// the 1st to the 3rd, the 5th, and the 7th to the 8th
+	if all := byLabel[""]; all.Longest != 3 || all.Current != 2 || !all.CurrentStart.Equal(day(7, 0)) {
+		t.Errorf("expected a longest streak of 3 days and a current one of 2 from the 7th, got %+v", all)
+	}
+	if coding := byLabel["Coding"]; coding.Longest != 3 || coding.Current != 1 || !coding.LongestEnd.Equal(day(3, 0)) {
+		t.Errorf("expected Coding to have a longest streak of 3 days and a current one of 1, got %+v", coding)
+	}
+	if reading := byLabel["Reading"]; reading.Longest != 2 {
+		t.Errorf("expected the Reading streak to break on the 6th, got %+v", reading)
+	}
+
+	nightOwl := timer.StreakOptions{Boundary: timer.DayBoundary{Hour: 4}, MinPomodoros: 1}
+	streaks, err = storage.ComputeStreaks(nightOwl, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range streaks {
+		if s.Label == "Reading" && s.Longest != 4 {
+			t.Errorf("expected the session at 1am to count toward the 6th, got %+v", s)
+		}
+	}
+
+	// two pomodoros a day, or the daily goal of the label
+	if err := storage.SetGoal(&timer.Goal{Period: timer.GoalDaily, Label: "Coding", Duration: 20 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	streaks, err = storage.ComputeStreaks(timer.StreakOptions{MinPomodoros: 2}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range streaks {
+		switch s.Label {
+		case "":
+			if s.Longest != 2 {
+				t.Errorf("expected only the 7th and 8th to have two pomodoros, got %+v", s)
+			}
+		case "Coding":
+			if s.Longest != 3 {
+				t.Errorf("expected the daily goal to decide the Coding streak, got %+v", s)
+			}
+		}
+	}
+
+	// the streak is over once a whole day is missed
+	streaks, err = storage.ComputeStreaks(timer.StreakOptions{MinPomodoros: 1}, day(10, 12))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streaks[0].Current != 0 {
+		t.Errorf("expected no current streak after a day off, got %+v", streaks[0])
+	}
+	if _, err := storage.ComputeStreaks(timer.StreakOptions{}, now); err == nil {
+		t.Error("expected an error for a minimum of 0 pomodoros")
+	}
+}
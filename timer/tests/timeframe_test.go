@@ -0,0 +1,290 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func TestParseTimeFrame(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2025, 9, 17, 16, 45, 0, 0, time.Local)
+
+	cases := []struct {
+		spec       string
+		start, end time.Time
+	}{
+		{"all", time.Time{}, time.Time{}},
+		{"today", day(2025, 9, 17), day(2025, 9, 18)},
+		{"yesterday", day(2025, 9, 16), day(2025, 9, 17)},
+		{"week", day(2025, 9, 15), day(2025, 9, 22)},
+		{"last-week", day(2025, 9, 8), day(2025, 9, 15)},
+		{"month", day(2025, 9, 1), day(2025, 10, 1)},
+		{"last-month", day(2025, 8, 1), day(2025, 9, 1)},
+		{"year", day(2025, 1, 1), day(2026, 1, 1)},
+		{"last-year", day(2024, 1, 1), day(2025, 1, 1)},
+		{"last-1d", day(2025, 9, 17), day(2025, 9, 18)},
+		{"last-7d", day(2025, 9, 11), day(2025, 9, 18)},
+		{"last-30d", day(2025, 8, 19), day(2025, 9, 18)},
+		{"last-2w", day(2025, 9, 4), day(2025, 9, 18)},
+		{"2025-02-28", day(2025, 2, 28), day(2025, 3, 1)},
+		{"2025-09", day(2025, 9, 1), day(2025, 10, 1)},
+		{"2024-12", day(2024, 12, 1), day(2025, 1, 1)},
+		{"2024", day(2024, 1, 1), day(2025, 1, 1)},
+	}
+	for _, c := range cases {
+		tf, err := timer.ParseTimeFrame(c.spec, now, timer.DayBoundary{}, time.Monday)
+		if err != nil {
+			t.Errorf("ParseTimeFrame(%q): %v", c.spec, err)
+			continue
+		}
+		if !tf.Start.Equal(c.start) || !tf.End.Equal(c.end) {
+			t.Errorf("ParseTimeFrame(%q) = %s – %s, want %s – %s", c.spec, tf.Start, tf.End, c.start, c.end)
+		}
+	}
+
+	for _, spec := range []string{"", "fortnight", "last-0d", "last-7", "last-7y", "2025-13", "next-week"} {
+		if _, err := timer.ParseTimeFrame(spec, now, timer.DayBoundary{}, time.Monday); err == nil {
+			t.Errorf("ParseTimeFrame(%q) should fail", spec)
+		}
+	}
+}
+
+func TestParseTimeFrameWeekStartsOnMonday(t *testing.T) {
+	for _, now := range []time.Time{
+		time.Date(2025, 9, 15, 0, 0, 0, 0, time.Local),  // Monday
+		time.Date(2025, 9, 21, 23, 0, 0, 0, time.Local), // Sunday
+	} {
+		tf, err := timer.ParseTimeFrame("week", now, timer.DayBoundary{}, time.Monday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tf.Start.Equal(day(2025, 9, 15)) {
+			t.Errorf("week of %s starts %s, want Monday 2025-09-15", now.Weekday(), tf.Start)
+		}
+	}
+}
+
+func TestParseTimeFrameDayStartAndWeekStart(t *testing.T) {
+	// 2am on Wednesday is still Tuesday for a day_start of 4
+	now := time.Date(2025, 9, 17, 2, 0, 0, 0, time.Local)
+	days := timer.DayBoundary{Hour: 4}
+	at4 := func(m time.Month, d int) time.Time {
+		return time.Date(2025, m, d, 4, 0, 0, 0, time.Local)
+	}
+
+	cases := []struct {
+		spec       string
+		start, end time.Time
+	}{
+		{"today", at4(9, 16), at4(9, 17)},
+		{"yesterday", at4(9, 15), at4(9, 16)},
+		{"week", at4(9, 14), at4(9, 21)},
+		{"last-week", at4(9, 7), at4(9, 14)},
+		{"month", at4(9, 1), at4(10, 1)},
+		{"last-7d", at4(9, 10), at4(9, 17)},
+		{"2025-09-17", at4(9, 17), at4(9, 18)},
+	}
+	for _, c := range cases {
+		tf, err := timer.ParseTimeFrame(c.spec, now, days, time.Sunday)
+		if err != nil {
+			t.Errorf("ParseTimeFrame(%q): %v", c.spec, err)
+			continue
+		}
+		if !tf.Start.Equal(c.start) || !tf.End.Equal(c.end) {
+			t.Errorf("ParseTimeFrame(%q) = %s – %s, want %s – %s", c.spec, tf.Start, tf.End, c.start, c.end)
+		}
+	}
+
+	tf, err := timer.ParseDateRange("2025-09-01", "today", now, days)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tf.Start.Equal(at4(9, 1)) || !tf.End.Equal(at4(9, 17)) {
+		t.Errorf("expected the range to run from the 1st to the end of Tuesday at 4am, got %s – %s", tf.Start, tf.End)
+	}
+
+	// days in the zone of the config, not the one of now
+	tokyo := time.FixedZone("JST", 9*60*60)
+	tf, err = timer.ParseTimeFrame("today", time.Date(2025, 9, 17, 20, 0, 0, 0, time.UTC), timer.DayBoundary{Location: tokyo}, time.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2025, 9, 18, 0, 0, 0, 0, tokyo); !tf.Start.Equal(want) {
+		t.Errorf("expected today to start at %s, got %s", want, tf.Start)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2025, 9, 17, 16, 45, 0, 0, time.Local)
+
+	cases := []struct {
+		from, to   string
+		start, end time.Time
+	}{
+		{"2025-09-01", "2025-09-30", day(2025, 9, 1), day(2025, 10, 1)},
+		{"2025-09-01 09:00", "2025-09-01 17:30", time.Date(2025, 9, 1, 9, 0, 0, 0, time.Local), time.Date(2025, 9, 1, 17, 30, 0, 0, time.Local)},
+		{"2025-09-10", "", day(2025, 9, 10), time.Time{}},
+		{"", "yesterday", time.Time{}, day(2025, 9, 17)},
+	}
+	for _, c := range cases {
+		tf, err := timer.ParseDateRange(c.from, c.to, now, timer.DayBoundary{})
+		if err != nil {
+			t.Errorf("ParseDateRange(%q, %q): %v", c.from, c.to, err)
+			continue
+		}
+		if !tf.Start.Equal(c.start) || !tf.End.Equal(c.end) {
+			t.Errorf("ParseDateRange(%q, %q) = %s – %s, want %s – %s", c.from, c.to, tf.Start, tf.End, c.start, c.end)
+		}
+	}
+
+	if _, err := timer.ParseDateRange("2025-09-30", "2025-09-01", now, timer.DayBoundary{}); err == nil {
+		t.Error("expected a range that ends before it starts to fail")
+	}
+}
+
+func TestComputePomoStatsTimeFrame(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	for _, start := range []time.Time{
+		time.Date(2025, 8, 31, 23, 30, 0, 0, time.Local),
+		time.Date(2025, 9, 1, 9, 0, 0, 0, time.Local),
+		time.Date(2025, 9, 30, 23, 0, 0, 0, time.Local),
+		time.Date(2025, 10, 1, 0, 0, 0, 0, time.Local),
+	} {
+		s := timer.Session{Label: "Work", Kind: timer.KindWork, StartTime: start, EndTime: start.Add(25 * time.Minute)}
+		if err := storage.SaveTimerData(&s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tf, err := timer.ParseTimeFrame("2025-09", time.Now(), timer.DayBoundary{}, time.Monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := storage.ComputePomoStats(tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalSessions != 2 {
+		t.Errorf("expected the 2 sessions started in September, got %d", stats.TotalSessions)
+	}
+}
+
+func TestTimeFramePrevious(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2025, 9, 17, 16, 45, 0, 0, time.Local)
+
+	cases := []struct {
+		spec       string
+		start, end time.Time
+	}{
+		{"today", day(2025, 9, 16), day(2025, 9, 17)},
+		{"week", day(2025, 9, 8), day(2025, 9, 15)},
+		{"last-30d", day(2025, 7, 20), day(2025, 8, 19)},
+		// the previous calendar month, not the 30 days before
+		{"month", day(2025, 8, 1), day(2025, 9, 1)},
+		{"2025-03", day(2025, 2, 1), day(2025, 3, 1)},
+		{"year", day(2024, 1, 1), day(2025, 1, 1)},
+	}
+	for _, c := range cases {
+		tf, err := timer.ParseTimeFrame(c.spec, now, timer.DayBoundary{}, time.Monday)
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		previous, err := tf.Previous()
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		if !previous.Start.Equal(c.start) || !previous.End.Equal(c.end) {
+			t.Errorf("%s: expected %s – %s, got %s – %s", c.spec, c.start, c.end, previous.Start, previous.End)
+		}
+	}
+
+	if _, err := (timer.TimeFrame{}).Previous(); err == nil {
+		t.Error("expected all time to have no previous period")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	current := &timer.PomoStats{
+		TotalSessions:     4,
+		TotalWorkDuration: 2 * time.Hour,
+		CompletionRate:    0.75,
+		PomosPerLabel:     map[string]int{"Coding": 3, "Reading": 1},
+		TimeSpentPerLabel: map[string]time.Duration{"Coding": 90 * time.Minute, "Reading": 30 * time.Minute},
+	}
+	previous := &timer.PomoStats{
+		TotalSessions:     2,
+		TotalWorkDuration: 3 * time.Hour,
+		CompletionRate:    1,
+		PomosPerLabel:     map[string]int{"Coding": 2, "Writing": 1},
+		TimeSpentPerLabel: map[string]time.Duration{"Coding": 2 * time.Hour, "Writing": time.Hour},
+	}
+
+	deltas := make(map[string]timer.Delta)
+	for _, d := range timer.Compare(current, previous).Deltas {
+		deltas[d.Metric+"/"+d.Label] = d
+	}
+	if d := deltas["total_sessions/"]; d.Change() != 2 {
+		t.Errorf("expected 2 more sessions, got %+v", d)
+	}
+	if p, ok := deltas["total_work/"].Percent(); !ok || p != -1.0/3 {
+		t.Errorf("expected a third less work, got %v", p)
+	}
+	if _, ok := deltas["sessions/Reading"].Percent(); ok {
+		t.Error("expected no percentage for a label that is new")
+	}
+	if d, ok := deltas["work/Writing"]; !ok || d.Current != 0 || d.Previous != 3600 {
+		t.Errorf("expected the work of a label only in the previous period, got %+v", d)
+	}
+}
+
+func TestCompareSessionAcrossPeriods(t *testing.T) {
+	storage := newTestSQLiteStorage(t)
+	defer storage.Close()
+
+	// an hour of Reading from 11:30pm the day before yesterday, so only
+	// its time is in either period
+	err := storage.SaveTimerData(&timer.Session{
+		Label:     "Reading",
+		Kind:      timer.KindWork,
+		Status:    timer.StatusCompleted,
+		StartTime: time.Date(2025, 9, 15, 23, 30, 0, 0, time.Local),
+		EndTime:   time.Date(2025, 9, 16, 0, 30, 0, 0, time.Local),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := timer.TimeFrame{Start: day(2025, 9, 17), End: day(2025, 9, 18)}
+	yesterday, err := today.Previous()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := storage.ComputePomoStats(today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, err := storage.ComputePomoStats(yesterday)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deltas := make(map[string]timer.Delta)
+	for _, d := range timer.Compare(current, previous).Deltas {
+		deltas[d.Metric+"/"+d.Label] = d
+	}
+	if d, ok := deltas["work/Reading"]; !ok || d.Current != 0 || d.Previous != 1800 {
+		t.Errorf("expected half an hour of Reading yesterday, got %+v", d)
+	}
+	if d, ok := deltas["sessions/Reading"]; !ok || d.Current != 0 || d.Previous != 0 {
+		t.Errorf("expected no Reading sessions in either period, got %+v", d)
+	}
+}
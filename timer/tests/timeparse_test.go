@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestParseTime(t *testing.T) {
+	ref := time.Date(2025, 9, 17, 16, 45, 0, 0, time.Local)
+
+	cases := []struct {
+		input string
+		want  time.Time
+	}{
+		{"2025-09-17 14:00", time.Date(2025, 9, 17, 14, 0, 0, 0, time.Local)},
+		{"2025-09-16 14:00:30", time.Date(2025, 9, 16, 14, 0, 30, 0, time.Local)},
+		{"2025-09-16T09:15", time.Date(2025, 9, 16, 9, 15, 0, 0, time.Local)},
+		{"2025/09/16 09:15", time.Date(2025, 9, 16, 9, 15, 0, 0, time.Local)},
+		{"2025-09-16T09:15:00Z", time.Date(2025, 9, 16, 9, 15, 0, 0, time.UTC)},
+		{"2025-09-16", time.Date(2025, 9, 16, 0, 0, 0, 0, time.Local)},
+		{"2025-09-16 3pm", time.Date(2025, 9, 16, 15, 0, 0, 0, time.Local)},
+		{"Sep 16 2025 9:30am", time.Date(2025, 9, 16, 9, 30, 0, 0, time.Local)},
+		{"16 Sep 2025 10:00", time.Date(2025, 9, 16, 10, 0, 0, 0, time.Local)},
+		{"sep 3 08:00", time.Date(2025, 9, 3, 8, 0, 0, 0, time.Local)},
+		{"15:30", time.Date(2025, 9, 17, 15, 30, 0, 0, time.Local)},
+		{"2:30pm", time.Date(2025, 9, 17, 14, 30, 0, 0, time.Local)},
+		{"9 AM", time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)},
+		{"today 8:00", time.Date(2025, 9, 17, 8, 0, 0, 0, time.Local)},
+		{"yesterday 16:00", time.Date(2025, 9, 16, 16, 0, 0, 0, time.Local)},
+		{"Yesterday", time.Date(2025, 9, 16, 0, 0, 0, 0, time.Local)},
+		{"now", ref},
+	}
+	for _, c := range cases {
+		got, err := timer.ParseTime(c.input, ref)
+		if err != nil {
+			t.Errorf("ParseTime(%q): %v", c.input, err)
+			continue
+		}
+		if !got.Equal(c.want) {
+			t.Errorf("ParseTime(%q) = %s, want %s", c.input, got, c.want)
+		}
+	}
+
+	for _, input := range []string{"", "tomorrow", "25:00", "yesterday noon", "2025-13-01"} {
+		if _, err := timer.ParseTime(input, ref); err == nil {
+			t.Errorf("ParseTime(%q) should fail", input)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Dima-salang/pomolite/timer"
+)
+
+func TestPomodoroCycleWithFakeClock(t *testing.T) {
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	clock := timer.NewFakeClock(start)
+
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+	pt.LongBreakDuration = 15 * time.Minute
+	pt.LongBreakEvery = 2
+
+	intervals := make(chan timer.Session, 8)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Session != nil {
+			intervals <- *event.Session
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 2; i++ {
+			if !pt.Start() {
+				done <- false
+				return
+			}
+		}
+		done <- true
+	}()
+
+	// advance wakes the running countdown and returns the interval it ends
+	advance := func(d time.Duration) timer.Session {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+		return <-intervals
+	}
+
+	// first work interval with a 3-minute pause after 10 minutes
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Minute)
+	pt.ControlChan <- "pause"
+	waitForPauseFlag(pt, true)
+	clock.Advance(3 * time.Minute)
+	pt.ControlChan <- "resume"
+	waitForPauseFlag(pt, false)
+	clock.Advance(15 * time.Minute)
+	work := <-intervals
+
+	shortBreak := advance(5 * time.Minute)
+	secondWork := advance(25 * time.Minute)
+	longBreak := advance(15 * time.Minute)
+
+	if ok := <-done; !ok {
+		t.Fatal("Expected the timer to run both rounds")
+	}
+
+	tests := []struct {
+		name    string
+		session timer.Session
+		kind    timer.SessionKind
+		gross   time.Duration
+		net     time.Duration
+	}{
+		{"first work", work, timer.KindWork, 28 * time.Minute, 25 * time.Minute},
+		{"short break", shortBreak, timer.KindShortBreak, 5 * time.Minute, 5 * time.Minute},
+		{"second work", secondWork, timer.KindWork, 25 * time.Minute, 25 * time.Minute},
+		{"long break", longBreak, timer.KindLongBreak, 15 * time.Minute, 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		if tt.session.Kind != tt.kind {
+			t.Errorf("%s: expected kind %s, got %s", tt.name, tt.kind, tt.session.Kind)
+		}
+		if tt.session.Status != timer.StatusCompleted {
+			t.Errorf("%s: expected status completed, got %s", tt.name, tt.session.Status)
+		}
+		if gross := tt.session.Duration(); gross != tt.gross {
+			t.Errorf("%s: expected gross duration %s, got %s", tt.name, tt.gross, gross)
+		}
+		if net := tt.session.NetDuration(); net != tt.net {
+			t.Errorf("%s: expected net duration %s, got %s", tt.name, tt.net, net)
+		}
+	}
+
+	if len(work.Pauses) != 1 || !work.Pauses[0].StartTime.Equal(start.Add(10*time.Minute)) {
+		t.Errorf("Expected one pause starting at 09:10, got %+v", work.Pauses)
+	}
+	if pt.Cycle != 2 {
+		t.Errorf("Expected 2 completed work intervals, got %d", pt.Cycle)
+	}
+}
+
+// wait until the countdown has handled a pause or resume command
+func waitForPauseFlag(pt *timer.PomodoroTimer, paused bool) {
+	for pt.PauseFlag.Load() != paused {
+		runtime.Gosched()
+	}
+}
+
+func TestQuitAbortsInterval(t *testing.T) {
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+
+	intervals := make(chan timer.Session, 1)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Session != nil {
+			intervals <- *event.Session
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		done <- pt.Start()
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(7 * time.Minute)
+	pt.ControlChan <- "quit"
+
+	if ok := <-done; ok {
+		t.Fatal("Expected Start to report the timer was stopped")
+	}
+	session := <-intervals
+	if session.Status != timer.StatusAborted {
+		t.Fatalf("Expected status aborted, got %s", session.Status)
+	}
+	if session.Duration() != 7*time.Minute {
+		t.Fatalf("Expected 7m, got %s", session.Duration())
+	}
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	start := time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local)
+	checkpoint := timer.State{
+		Running:          true,
+		Label:            "Test",
+		Phase:            timer.KindWork,
+		Description:      "Test [#1]",
+		Round:            1,
+		Position:         "[#1]",
+		RemainingSeconds: int64((10 * time.Minute).Seconds()),
+		PlannedSeconds:   int64((25 * time.Minute).Seconds()),
+		IntervalStart:    start,
+		UpdatedAt:        start.Add(15 * time.Minute),
+	}
+
+	// the process died at 09:15 and the timer is resumed at 10:00
+	clock := timer.NewFakeClock(start.Add(time.Hour))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+
+	intervals := make(chan timer.Session, 2)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Session != nil {
+			intervals <- *event.Session
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		done <- pt.Resume(checkpoint)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Minute)
+	work := <-intervals
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Minute)
+	<-intervals
+
+	if ok := <-done; !ok {
+		t.Fatal("Expected the resumed timer to finish its break")
+	}
+	if !work.StartTime.Equal(start) {
+		t.Errorf("Expected the resumed session to start at 09:00, got %s", work.StartTime)
+	}
+	if work.Status != timer.StatusCompleted {
+		t.Errorf("Expected status completed, got %s", work.Status)
+	}
+	if net := work.NetDuration(); net != 25*time.Minute {
+		t.Errorf("Expected 25m net duration, got %s", net)
+	}
+	if pt.Cycle != 1 {
+		t.Errorf("Expected 1 completed work interval, got %d", pt.Cycle)
+	}
+}
+
+func TestSignalQuitsTimer(t *testing.T) {
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		timer.QuitOnSignal(signals, pt.ControlChan, done)
+		close(exited)
+	}()
+
+	stopped := make(chan bool)
+	go func() {
+		stopped <- pt.Start()
+	}()
+	clock.BlockUntil(1)
+	signals <- syscall.SIGTERM
+	if ok := <-stopped; ok {
+		t.Fatal("Expected a signal to stop the timer")
+	}
+	close(done)
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the signal handler to exit")
+	}
+}
+
+func TestSignalHandlerExitsAfterRunLoop(t *testing.T) {
+	tests := []struct {
+		name   string
+		signal bool
+	}{
+		{"without a signal", false},
+		// a signal that arrives once nothing reads the control channel
+		{"with a late signal", true},
+	}
+	for _, tt := range tests {
+		signals := make(chan os.Signal, 1)
+		control := make(chan string)
+		done := make(chan struct{})
+		exited := make(chan struct{})
+		go func() {
+			timer.QuitOnSignal(signals, control, done)
+			close(exited)
+		}()
+
+		// the run loop has returned
+		close(done)
+		if tt.signal {
+			signals <- syscall.SIGINT
+		}
+		select {
+		case <-exited:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: expected the signal handler to exit once the run loop returned", tt.name)
+		}
+	}
+}
+
+func TestSkippedWorkDoesNotCount(t *testing.T) {
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+	pt.LongBreakDuration = 15 * time.Minute
+	pt.LongBreakEvery = 2
+
+	ended := make(chan timer.Event, 1)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Session != nil {
+			ended <- event
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 3; i++ {
+			if !pt.Start() {
+				done <- false
+				return
+			}
+		}
+		done <- true
+	}()
+
+	// finish runs the countdown to the end and skip cuts it short, both
+	// return the event that ends the interval
+	finish := func(d time.Duration) timer.Event {
+		clock.BlockUntil(1)
+		clock.Advance(d)
+		return <-ended
+	}
+	skip := func() timer.Event {
+		clock.BlockUntil(1)
+		pt.ControlChan <- "skip"
+		return <-ended
+	}
+
+	tests := []struct {
+		name   string
+		event  timer.Event
+		kind   timer.SessionKind
+		status timer.SessionStatus
+		round  int
+	}{
+		{"first work", finish(25 * time.Minute), timer.KindWork, timer.StatusCompleted, 1},
+		{"first break", finish(5 * time.Minute), timer.KindShortBreak, timer.StatusCompleted, 1},
+		{"skipped work", skip(), timer.KindWork, timer.StatusSkipped, 2},
+		// a skipped interval does not earn the long break
+		{"break after the skip", finish(5 * time.Minute), timer.KindShortBreak, timer.StatusCompleted, 2},
+		{"second work", finish(25 * time.Minute), timer.KindWork, timer.StatusCompleted, 2},
+		{"long break", finish(15 * time.Minute), timer.KindLongBreak, timer.StatusCompleted, 2},
+	}
+	if ok := <-done; !ok {
+		t.Fatal("Expected the timer to run three rounds")
+	}
+	for _, tt := range tests {
+		session := tt.event.Session
+		if session.Kind != tt.kind || session.Status != tt.status || tt.event.Round != tt.round {
+			t.Errorf("%s: expected %s %s in round %d, got %s %s in round %d", tt.name, tt.status, tt.kind, tt.round, session.Status, session.Kind, tt.event.Round)
+		}
+	}
+	if pt.Cycle != 2 {
+		t.Errorf("Expected 2 completed work intervals, got %d", pt.Cycle)
+	}
+}
+
+func TestSkippingEveryWorkIntervalCompletesNoCycle(t *testing.T) {
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+
+	ended := make(chan struct{}, 1)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Session != nil {
+			ended <- struct{}{}
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		// how 'pomo start --cycles 1' runs the timer
+		for pt.Start() {
+			if pt.Cycle >= 1 {
+				done <- true
+				return
+			}
+		}
+		done <- false
+	}()
+
+	// skip three work intervals and their breaks
+	for i := 0; i < 6; i++ {
+		clock.BlockUntil(1)
+		pt.ControlChan <- "skip"
+		<-ended
+	}
+	clock.BlockUntil(1)
+	pt.ControlChan <- "quit"
+	<-ended
+
+	if completed := <-done; completed {
+		t.Fatal("Expected skipped work intervals not to complete a cycle")
+	}
+	if pt.Cycle != 0 {
+		t.Errorf("Expected no completed work intervals, got %d", pt.Cycle)
+	}
+}
+
+func TestResumeBreakAfterSkippedWork(t *testing.T) {
+	clock := timer.NewFakeClock(time.Date(2025, 9, 17, 9, 0, 0, 0, time.Local))
+	path := filepath.Join(t.TempDir(), "state.json")
+	pt := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+	pt.Subscribe(timer.NewStateFile(path, pt))
+
+	// the state file as the process leaves it if it dies once the break
+	// after a skipped work interval started
+	checkpoints := make(chan timer.State, 1)
+	pt.Subscribe(timer.ObserverFunc(func(event timer.Event) {
+		if event.Type == timer.IntervalStarted && event.Kind == timer.KindShortBreak {
+			state, err := timer.ReadStateFile(path)
+			if err != nil {
+				t.Error(err)
+			}
+			checkpoints <- state
+		}
+	}))
+
+	done := make(chan bool)
+	go func() {
+		done <- pt.Start()
+	}()
+	clock.BlockUntil(1)
+	pt.ControlChan <- "skip"
+	checkpoint := <-checkpoints
+	clock.BlockUntil(1)
+	pt.ControlChan <- "quit"
+	<-done
+
+	resumed := timer.NewPomodoroTimer(25*time.Minute, 5*time.Minute, "Test", timer.WithClock(clock))
+	go func() {
+		done <- resumed.Resume(checkpoint)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(5 * time.Minute)
+	if ok := <-done; !ok {
+		t.Fatal("Expected the resumed timer to finish its break")
+	}
+	if resumed.Cycle != 0 {
+		t.Errorf("Expected the skipped work interval not to count once resumed, got %d", resumed.Cycle)
+	}
+}
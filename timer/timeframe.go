@@ -0,0 +1,230 @@
+package timer
+
+// Date ranges for the statistics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// TimeFrame is the range of start times from Start, inclusive, to End,
+// exclusive. A zero Start or End leaves that side open, so the zero value
+// covers all time.
+type TimeFrame struct {
+	Start time.Time
+	End   time.Time
+	// Name is how the frame was asked for, e.g. "last-7d" or "2025-09".
+	Name string
+}
+
+// String names the frame and gives its dates, e.g. "week (2025-09-15 – 2025-09-21)".
+func (tf TimeFrame) String() string {
+	if tf.Start.IsZero() && tf.End.IsZero() {
+		if tf.Name == "" {
+			return "all"
+		}
+		return tf.Name
+	}
+
+	start, end := "…", "…"
+	if !tf.Start.IsZero() {
+		start = tf.Start.Format("2006-01-02")
+	}
+	if !tf.End.IsZero() {
+		// the last day in the frame, as people count the end of a range
+		last := tf.End.Add(-time.Nanosecond)
+		if last.Before(tf.Start) {
+			last = tf.Start
+		}
+		end = last.Format("2006-01-02")
+	}
+
+	dates := start + " – " + end
+	if start == end {
+		dates = start
+	}
+	if tf.Name == "" || tf.Name == dates {
+		return dates
+	}
+	return fmt.Sprintf("%s (%s)", tf.Name, dates)
+}
+
+// Previous is the frame of the same length that ends where this one
+// starts. Frames of whole calendar months go back by as many months, and
+// frames of whole days by as many days, so that "month" is compared with
+// the previous month and "week" with the previous week across DST changes.
+func (tf TimeFrame) Previous() (TimeFrame, error) {
+	if tf.Start.IsZero() || tf.End.IsZero() {
+		return TimeFrame{}, fmt.Errorf("%s has no start or end, so there is no previous period", tf)
+	}
+
+	var start time.Time
+	months := (tf.End.Year()-tf.Start.Year())*12 + int(tf.End.Month()-tf.Start.Month())
+	days := DaysBetween(tf.Start, tf.End)
+	switch {
+	case months > 0 && tf.Start.Day() == 1 && tf.Start.AddDate(0, months, 0).Equal(tf.End):
+		start = tf.Start.AddDate(0, -months, 0)
+	case days > 0 && tf.Start.AddDate(0, 0, days).Equal(tf.End):
+		start = tf.Start.AddDate(0, 0, -days)
+	default:
+		start = tf.Start.Add(-tf.End.Sub(tf.Start))
+	}
+	return TimeFrame{Start: start, End: tf.Start}, nil
+}
+
+// DaysBetween is the number of calendar days from a to b in the location of
+// a, which is not their difference in hours over 24 across a DST change.
+func DaysBetween(a, b time.Time) int {
+	b = b.In(a.Location())
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// bounds are the frame in unix seconds, for `start_time >= ? AND
+// start_time < ?`
+func (tf TimeFrame) bounds() (int64, int64) {
+	var start, end int64 = math.MinInt64, math.MaxInt64
+	if !tf.Start.IsZero() {
+		start = tf.Start.Unix()
+	}
+	if !tf.End.IsZero() {
+		end = tf.End.Unix()
+	}
+	return start, end
+}
+
+// last-7d, last-2w and the like
+var rollingWindow = regexp.MustCompile(`^last-(\d+)([dw])$`)
+
+// ParseTimeFrame reads a named time frame relative to now, with days
+// decided by days and weeks starting on weekStart, as for goals and streaks:
+//
+//	all                     all time
+//	today, yesterday        a single day
+//	week, last-week         the current or previous week
+//	month, last-month       the current or previous calendar month
+//	year, last-year         the current or previous calendar year
+//	last-7d, last-2w        the last days or weeks, today included
+//	2025-09-17              a single day
+//	2025-09                 a calendar month
+//	2025                    a calendar year
+func ParseTimeFrame(spec string, now time.Time, days DayBoundary, weekStart time.Weekday) (TimeFrame, error) {
+	// the frames are worked out in days, as midnight of their dates, and
+	// start when the first of them does
+	loc := days.location()
+	today := days.Day(now)
+	week := days.Week(now, weekStart)
+	firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+	firstOfYear := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, loc)
+
+	tf := TimeFrame{Name: spec}
+	span := func(first, end time.Time) (TimeFrame, error) {
+		tf.Start, tf.End = days.Start(first), days.Start(end)
+		return tf, nil
+	}
+	switch spec {
+	case "all":
+		return tf, nil
+	case "today":
+		return span(today, today.AddDate(0, 0, 1))
+	case "yesterday":
+		return span(today.AddDate(0, 0, -1), today)
+	case "week":
+		return span(week, week.AddDate(0, 0, 7))
+	case "last-week":
+		return span(week.AddDate(0, 0, -7), week)
+	case "month":
+		return span(firstOfMonth, firstOfMonth.AddDate(0, 1, 0))
+	case "last-month":
+		return span(firstOfMonth.AddDate(0, -1, 0), firstOfMonth)
+	case "year":
+		return span(firstOfYear, firstOfYear.AddDate(1, 0, 0))
+	case "last-year":
+		return span(firstOfYear.AddDate(-1, 0, 0), firstOfYear)
+	default:
+		if m := rollingWindow.FindStringSubmatch(spec); m != nil {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n <= 0 || n > 100000 {
+				return TimeFrame{}, fmt.Errorf("invalid timeframe: %s", spec)
+			}
+			if m[2] == "w" {
+				n *= 7
+			}
+			return span(today.AddDate(0, 0, 1-n), today.AddDate(0, 0, 1))
+		}
+		if t, err := time.ParseInLocation("2006-01-02", spec, loc); err == nil {
+			return span(t, t.AddDate(0, 0, 1))
+		}
+		if t, err := time.ParseInLocation("2006-01", spec, loc); err == nil {
+			return span(t, t.AddDate(0, 1, 0))
+		}
+		if t, err := time.ParseInLocation("2006", spec, loc); err == nil {
+			return span(t, t.AddDate(1, 0, 0))
+		}
+		return TimeFrame{}, fmt.Errorf("invalid timeframe: %s", spec)
+	}
+}
+
+// ParseDateRange builds a time frame from the times at which it starts and
+// ends, in the formats of ParseTime and in the location of days. Either may
+// be empty to leave that side open. A bare date is a day as decided by days,
+// and as the end, like "2025-09-30", includes that whole day.
+func ParseDateRange(from string, to string, now time.Time, days DayBoundary) (TimeFrame, error) {
+	// ParseTime takes "today" by the calendar, not by days
+	now = now.In(days.location())
+	today := days.Day(now)
+	parse := func(value string, end bool) (time.Time, error) {
+		if !isBareDate(value) {
+			return ParseTime(value, now)
+		}
+		day := today
+		if value != "today" {
+			var err error
+			if day, err = ParseTime(value, today); err != nil {
+				return time.Time{}, err
+			}
+		}
+		if end {
+			day = day.AddDate(0, 0, 1)
+		}
+		return days.Start(day), nil
+	}
+
+	var tf TimeFrame
+	if from != "" {
+		start, err := parse(from, false)
+		if err != nil {
+			return TimeFrame{}, fmt.Errorf("from: %w", err)
+		}
+		tf.Start = start
+	}
+	if to != "" {
+		end, err := parse(to, true)
+		if err != nil {
+			return TimeFrame{}, fmt.Errorf("to: %w", err)
+		}
+		tf.End = end
+	}
+	if !tf.Start.IsZero() && !tf.End.IsZero() && !tf.End.After(tf.Start) {
+		return TimeFrame{}, fmt.Errorf("the range ends before it starts")
+	}
+	return tf, nil
+}
+
+// reports whether s is a date without a time of day
+func isBareDate(s string) bool {
+	for _, layout := range []string{"2006-01-02", "2006/01/02", "2 Jan 2006", "Jan 2 2006"} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	switch s {
+	case "today", "yesterday":
+		return true
+	}
+	return false
+}
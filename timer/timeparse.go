@@ -0,0 +1,100 @@
+package timer
+
+// Parsing of the dates and times people type on the command line
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// layouts of a full date and time, tried in order
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2 Jan 2006 15:04",
+	"Jan 2 2006 15:04",
+	"2006-01-02 3:04pm",
+	"2006-01-02 3pm",
+	"Jan 2 2006 3:04pm",
+	"Jan 2 2006 3pm",
+	"2006-01-02",
+	"2006/01/02",
+	"2 Jan 2006",
+	"Jan 2 2006",
+}
+
+// layouts of a time of day, taken on the reference date
+var timeOfDayLayouts = []string{
+	"15:04:05",
+	"15:04",
+	"3:04pm",
+	"3pm",
+}
+
+// ParseTime reads a point in time such as "2025-09-17 14:00", "14:00",
+// "2:30pm", "yesterday 16:00", "Sep 17 2025 14:00" or "now". A time of day
+// alone is taken on the date of ref, and "today" and "yesterday" are
+// relative to it, so the end of a session can be given as just "15:30".
+// Times without a zone are in the location of ref.
+func ParseTime(value string, ref time.Time) (time.Time, error) {
+	s := strings.Join(strings.Fields(value), " ")
+	lower := strings.ToLower(s)
+	lower = strings.ReplaceAll(strings.ReplaceAll(lower, " am", "am"), " pm", "pm")
+	loc := ref.Location()
+
+	switch lower {
+	case "":
+		return time.Time{}, fmt.Errorf("empty time")
+	case "now":
+		return ref, nil
+	case "today":
+		return startOfDay(ref), nil
+	case "yesterday":
+		return startOfDay(ref).AddDate(0, 0, -1), nil
+	}
+
+	day, relative := ref, false
+	if rest, ok := strings.CutPrefix(lower, "today "); ok {
+		lower, relative = rest, true
+	} else if rest, ok := strings.CutPrefix(lower, "yesterday "); ok {
+		lower, relative = rest, true
+		day = ref.AddDate(0, 0, -1)
+	}
+
+	for _, layout := range timeOfDayLayouts {
+		if t, err := time.ParseInLocation(layout, lower, loc); err == nil {
+			y, m, d := day.Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+		}
+	}
+	if relative {
+		return time.Time{}, fmt.Errorf("unrecognized time of day in %q", value)
+	}
+
+	// layouts with a "T" need the input as typed, the ones with "pm" need
+	// it lowercased
+	for _, layout := range dateTimeLayouts {
+		for _, input := range []string{s, lower} {
+			if t, err := time.ParseInLocation(layout, input, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	// "Sep 17 14:00" is in the year of ref
+	if t, err := time.ParseInLocation("Jan 2 15:04", s, loc); err == nil {
+		return time.Date(ref.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q, use e.g. \"2025-09-17 14:00\", \"14:00\" or \"yesterday 2pm\"", value)
+}
+
+// midnight at the start of the day of t
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -1,120 +1,307 @@
 package timer
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"sync/atomic"
 	"time"
 
 	"github.com/eiannone/keyboard"
-	"github.com/fatih/color"
-	"github.com/gen2brain/beeep"
-	"github.com/schollz/progressbar/v3"
 )
 
 type PomodoroTimer struct {
 	WorkDuration  time.Duration
 	BreakDuration time.Duration
 	WorkLabel     string
+	Tags          []string
 	PauseFlag     atomic.Bool
 	ControlChan   chan string
 	StartTime     time.Time
 	EndTime       time.Time
+
+	// LongBreakDuration replaces the short break after every LongBreakEvery
+	// work intervals. A zero duration or interval disables long breaks.
+	LongBreakDuration time.Duration
+	LongBreakEvery    int
+
+	// Cycle is the number of work intervals completed so far.
+	Cycle int
+
+	observers []Observer
+	// template for the events of the interval that is currently counting down
+	current Event
+	// pauses taken during the interval that is currently counting down
+	pauses []Pause
+
+	clock Clock
 }
 
-func NewPomodoroTimer(workDuration time.Duration, breakDuration time.Duration, workLabel string) *PomodoroTimer {
-	return &PomodoroTimer{
+// Option configures a PomodoroTimer.
+type Option func(*PomodoroTimer)
+
+// WithClock makes the timer read time from the given clock instead of the
+// system clock.
+func WithClock(clock Clock) Option {
+	return func(pt *PomodoroTimer) {
+		pt.clock = clock
+	}
+}
+
+func NewPomodoroTimer(workDuration time.Duration, breakDuration time.Duration, workLabel string, opts ...Option) *PomodoroTimer {
+	pt := &PomodoroTimer{
 		WorkDuration:  workDuration,
 		BreakDuration: breakDuration,
 		WorkLabel:     workLabel,
 		PauseFlag:     atomic.Bool{},
 		ControlChan:   make(chan string),
-		StartTime:     time.Now(),
-		EndTime:       time.Now(),
+		clock:         RealClock{},
+	}
+	for _, opt := range opts {
+		opt(pt)
 	}
+	pt.StartTime = pt.clock.Now()
+	pt.EndTime = pt.StartTime
+	return pt
 }
 
-func (pt *PomodoroTimer) Start() bool {
-	fmt.Printf("Starting Pomodoro Timer for %s for %s with a break of %s\n", pt.WorkLabel, pt.WorkDuration.String(), pt.BreakDuration.String())
+// interval is a single work or break countdown
+type interval struct {
+	kind        SessionKind
+	round       int
+	position    string
+	description string
+	planned     time.Duration
+	// set when finishing an interval that was interrupted earlier
+	remaining time.Duration
+	startTime time.Time
+	pauses    []Pause
+}
 
-	if !pt.CountDownStart(pt.WorkLabel, pt.WorkDuration) {
+// Start runs one work interval followed by its break. Every LongBreakEvery
+// completed work intervals the short break is replaced by a long break. A
+// skipped work interval is followed by a short break but does not count as
+// a cycle, so the next Start runs the same round again.
+func (pt *PomodoroTimer) Start() bool {
+	round := pt.Cycle + 1
+	position := pt.CyclePosition()
+	status := pt.runInterval(interval{
+		kind:        KindWork,
+		round:       round,
+		position:    position,
+		description: fmt.Sprintf("%s %s", pt.WorkLabel, position),
+		planned:     pt.WorkDuration,
+	})
+	if status == StatusAborted {
 		return false
 	}
+	if status == StatusCompleted {
+		pt.Cycle++
+	}
 
-	fmt.Println("\nWork completed, good job! Take a break.")
+	return pt.takeBreak(round, position, status == StatusCompleted && pt.IsLongBreakDue())
+}
+
+// Resume finishes the interval recorded in a checkpoint of a timer that
+// stopped unexpectedly, then takes the break that follows it like Start
+// does. The time between the checkpoint and now is recorded as a pause.
+func (pt *PomodoroTimer) Resume(state State) bool {
+	now := pt.clock.Now()
+	pauses := append([]Pause(nil), state.Pauses...)
+	if n := len(pauses); n > 0 && pauses[n-1].EndTime.IsZero() {
+		pauses[n-1].EndTime = now
+	} else {
+		pauses = append(pauses, Pause{StartTime: state.UpdatedAt, EndTime: now})
+	}
 
-	if !pt.CountDownStart(pt.WorkLabel, pt.BreakDuration) {
+	pt.Cycle = state.Cycle
+	status := pt.runInterval(interval{
+		kind:        state.Phase,
+		round:       state.Round,
+		position:    state.Position,
+		description: state.Description,
+		planned:     state.Planned(),
+		remaining:   state.Remaining(),
+		startTime:   state.IntervalStart,
+		pauses:      pauses,
+	})
+	if status == StatusAborted {
 		return false
 	}
+	if state.Phase != KindWork {
+		return true
+	}
+	if status == StatusCompleted {
+		pt.Cycle++
+	}
 
-	fmt.Println("\nBreak completed. Back to work.")
+	return pt.takeBreak(state.Round, state.Position, status == StatusCompleted && pt.IsLongBreakDue())
+}
 
-	return true
+// takeBreak runs the short or long break after a work interval
+func (pt *PomodoroTimer) takeBreak(round int, position string, long bool) bool {
+	if long {
+		return pt.runInterval(interval{
+			kind:        KindLongBreak,
+			round:       round,
+			position:    position,
+			description: fmt.Sprintf("Long Break %s", position),
+			planned:     pt.LongBreakDuration,
+		}) != StatusAborted
+	}
+	return pt.runInterval(interval{
+		kind:        KindShortBreak,
+		round:       round,
+		position:    position,
+		description: fmt.Sprintf("Break %s", position),
+		planned:     pt.BreakDuration,
+	}) != StatusAborted
+}
+
+// runInterval counts down a single interval and publishes it as a session,
+// whether it ran to completion, was skipped or was aborted, and returns its
+// status. An aborted interval means the timer should stop.
+func (pt *PomodoroTimer) runInterval(iv interval) SessionStatus {
+	if iv.remaining <= 0 {
+		iv.remaining = iv.planned
+	}
+	if iv.startTime.IsZero() {
+		iv.startTime = pt.clock.Now()
+	}
+
+	pt.current = Event{
+		Kind:          iv.kind,
+		Label:         pt.WorkLabel,
+		Description:   iv.description,
+		Round:         iv.round,
+		Position:      iv.position,
+		Planned:       iv.planned,
+		IntervalStart: iv.startTime,
+	}
+	session := Session{
+		Kind:            iv.kind,
+		Label:           pt.WorkLabel,
+		Tags:            pt.Tags,
+		PlannedDuration: iv.planned,
+		StartTime:       iv.startTime,
+	}
+
+	pt.pauses = iv.pauses
+	pt.emit(IntervalStarted, iv.remaining, nil)
+
+	session.Status = pt.CountDownStart(iv.description, iv.remaining)
+	session.Pauses = pt.pauses
+	pt.pauses = nil
+
+	session.EndTime = pt.clock.Now()
+	pt.EndTime = session.EndTime
+
+	switch session.Status {
+	case StatusCompleted:
+		pt.emit(IntervalCompleted, 0, &session)
+	case StatusSkipped:
+		pt.emit(IntervalSkipped, 0, &session)
+	case StatusAborted:
+		pt.emit(Aborted, 0, &session)
+	}
+	return session.Status
+}
+
+// LongBreaksEnabled reports whether the timer alternates in long breaks.
+func (pt *PomodoroTimer) LongBreaksEnabled() bool {
+	return pt.LongBreakDuration > 0 && pt.LongBreakEvery > 0
+}
+
+// IsLongBreakDue reports whether the break after the last completed work
+// interval should be a long one.
+func (pt *PomodoroTimer) IsLongBreakDue() bool {
+	return pt.LongBreaksEnabled() && pt.Cycle > 0 && pt.Cycle%pt.LongBreakEvery == 0
 }
 
-func (pt *PomodoroTimer) CountDownStart(label string, duration time.Duration) bool {
-	bar := progressbar.NewOptions64(
-		int64(duration.Seconds()),
-		progressbar.OptionSetWidth(30),
-		progressbar.OptionShowCount(),
-		progressbar.OptionClearOnFinish(),
-		progressbar.OptionSetDescription(color.CyanString(
-			"▶ %s [%02d:%02d]", label,
-			int(duration.Minutes()), int(duration.Seconds())%60,
-		)),
-		progressbar.OptionSetTheme(progressbar.Theme{
-			Saucer:        "█",
-			SaucerPadding: "░",
-			BarStart:      "[",
-			BarEnd:        "]",
-		}),
-	)
-
-	ticker := time.NewTicker(time.Second)
+// CyclePosition describes the current work interval, e.g. "[2/4]" within a
+// long-break cycle or "[#7]" when long breaks are disabled.
+func (pt *PomodoroTimer) CyclePosition() string {
+	round := pt.Cycle + 1
+	if !pt.LongBreaksEnabled() {
+		return fmt.Sprintf("[#%d]", round)
+	}
+	return fmt.Sprintf("[%d/%d]", (round-1)%pt.LongBreakEvery+1, pt.LongBreakEvery)
+}
+
+// CountDownStart counts down the duration and reports how the interval ended:
+// completed, skipped by the user, or aborted by quitting the timer.
+func (pt *PomodoroTimer) CountDownStart(label string, duration time.Duration) SessionStatus {
+	ticker := pt.clock.NewTicker(time.Second)
 	defer ticker.Stop()
 
 	for remaining := duration; remaining > 0; {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			if !pt.PauseFlag.Load() {
 				remaining -= time.Second
-				bar.Add(1)
-
-				// Update label with time left (mm:ss), cyan text
-				bar.Describe(color.CyanString("▶ %s [%02d:%02d]", label,
-					int(remaining.Minutes()), int(remaining.Seconds())%60))
+				pt.emit(Tick, remaining, nil)
 			}
 		case cmd := <-pt.ControlChan:
 			switch cmd {
 			case "pause":
-				pt.PauseFlag.Store(true)
-				bar.Describe(color.YellowString("⏸ Paused - press 'r' to resume"))
+				if pt.pause() {
+					pt.emit(Paused, remaining, nil)
+				}
 			case "resume":
-				pt.PauseFlag.Store(false)
-				bar.Describe(color.GreenString("▶ Resumed: %s", label))
+				if pt.resume() {
+					pt.emit(Resumed, remaining, nil)
+				}
+			case "skip":
+				pt.resume()
+				return StatusSkipped
 			case "quit":
-				fmt.Println(color.RedString("\n⏹ Timer stopped early."))
-				return false
+				pt.resume()
+				return StatusAborted
 			}
 		}
 	}
 
-	// Completion feedback
-	fmt.Println(color.GreenString("\n✅ %s completed!", label))
-
-	// Desktop notification
-	err := beeep.Notify(pt.WorkLabel, fmt.Sprintf("%s completed!", label), "")
-
-	// Terminal beep (may or may not work depending on system)
-	fmt.Print("\a")
-	beeep.Beep(500, 200)
+	return StatusCompleted
+}
 
-	if err != nil {
-		fmt.Println(color.RedString("Error sending notification: %v", err))
+// pause the countdown and open a pause interval, unless already paused. The
+// flag is set last so that anyone watching it sees the pause recorded.
+func (pt *PomodoroTimer) pause() bool {
+	if pt.PauseFlag.Load() {
+		return false
 	}
+	pt.pauses = append(pt.pauses, Pause{StartTime: pt.clock.Now()})
+	pt.PauseFlag.Store(true)
+	return true
+}
 
+// resume the countdown and close the open pause interval, if any
+func (pt *PomodoroTimer) resume() bool {
+	if !pt.PauseFlag.Load() {
+		return false
+	}
+	if n := len(pt.pauses); n > 0 {
+		pt.pauses[n-1].EndTime = pt.clock.Now()
+	}
+	pt.PauseFlag.Store(false)
 	return true
 }
 
+// QuitOnSignal sends "quit" to the control channel on the first signal. It
+// returns once done is closed, as nothing reads the control channel after
+// the run loop returns.
+func QuitOnSignal(signals <-chan os.Signal, controlChan chan<- string, done <-chan struct{}) {
+	select {
+	case <-signals:
+	case <-done:
+		return
+	}
+	select {
+	case controlChan <- "quit":
+	case <-done:
+	}
+}
+
 // listening for commands
 func ListenForCommands(controlChan chan<- string) {
 	if err := keyboard.Open(); err != nil {
@@ -123,16 +310,23 @@ func ListenForCommands(controlChan chan<- string) {
 	}
 	defer keyboard.Close()
 	for {
-		cmd_input, _, err := keyboard.GetKey()
+		cmd_input, key, err := keyboard.GetKey()
 		if err != nil {
 			fmt.Println("Error: ", err)
 			return
 		}
+		// raw mode swallows the SIGINT of Ctrl+C, treat it like 'q'
+		if key == keyboard.KeyCtrlC {
+			controlChan <- "quit"
+			return
+		}
 		switch cmd_input {
 		case 'p':
 			controlChan <- "pause"
 		case 'r':
 			controlChan <- "resume"
+		case 's':
+			controlChan <- "skip"
 		case 'q':
 			controlChan <- "quit"
 			return
@@ -149,3 +343,25 @@ func CheckInput(minutes int, breakMinutes int) bool {
 
 	return true
 }
+
+func CheckLongBreakInput(longBreakMinutes int, longEvery int) bool {
+	if err := ValidateLongBreak(longBreakMinutes, longEvery); err != nil {
+		fmt.Println("Error:", err)
+		return false
+	}
+
+	return true
+}
+
+// ValidateLongBreak checks the long break settings of a timer, for the
+// command line and the daemon alike.
+func ValidateLongBreak(longBreakMinutes int, longEvery int) error {
+	// a long break of 0 minutes disables long breaks, negative values are invalid
+	if longBreakMinutes < 0 || longEvery < 0 {
+		return errors.New("Invalid input. Long break and long break interval must not be negative.")
+	}
+	if longBreakMinutes > 0 && longEvery == 0 {
+		return errors.New("Invalid input. Long break interval must be greater than 0 when a long break is set.")
+	}
+	return nil
+}